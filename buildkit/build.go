@@ -1,49 +1,81 @@
 package buildkit
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/docker/cli/cli/config"
 	"github.com/moby/buildkit/client"
 	_ "github.com/moby/buildkit/client/connhelper/dockercontainer"
 	_ "github.com/moby/buildkit/client/connhelper/nerdctlcontainer"
 	"github.com/moby/buildkit/client/llb"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
 	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/auth/authprovider"
 	"github.com/moby/buildkit/session/secrets/secretsprovider"
 	"github.com/moby/buildkit/util/appcontext"
+	"github.com/moby/buildkit/util/entitlements"
 	_ "github.com/moby/buildkit/util/grpcutil/encoding/proto"
 	"github.com/moby/buildkit/util/progress/progressui"
 	"github.com/railwayapp/railpack/core/plan"
 	"github.com/tonistiigi/fsutil"
 )
 
+var (
+	cacheImportTypes = []string{"local", "registry", "s3", "gha"}
+	cacheExportTypes = []string{"local", "registry", "inline", "s3", "gha"}
+)
+
 type BuildWithBuildkitClientOptions struct {
 	ImageName    string
 	DumpLLB      bool
 	OutputDir    string
+	OutputTar    string
+	OutputFormat string
+	Push         bool
 	ProgressMode string
 	SecretsHash  string
 	Secrets      map[string]string
-	Platform     BuildPlatform
-	ImportCache  string
-	ExportCache  string
+	Platforms    []BuildPlatform
+	ImportCache  []string
+	ExportCache  []string
 	CacheKey     string
 }
 
 func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWithBuildkitClientOptions) error {
 	ctx := appcontext.Context()
 
+	if opts.Push && opts.ImageName == "" {
+		return fmt.Errorf("an image name including the registry (e.g. registry.example.com/repo:tag) is required to push")
+	}
+
+	outputCount := 0
+	for _, output := range []bool{opts.Push, opts.OutputDir != "", opts.OutputTar != ""} {
+		if output {
+			outputCount++
+		}
+	}
+	if outputCount > 1 {
+		return fmt.Errorf("only one of pushing the image, saving the filesystem to a directory, or writing a tarball can be used")
+	}
+
 	imageName := opts.ImageName
 	if imageName == "" {
 		imageName = getImageName(appDir)
 	}
 
+	// Load the image into Docker unless it is exported some other way
+	useDockerLoad := outputCount == 0
+
 	buildkitHost := os.Getenv("BUILDKIT_HOST")
 	if buildkitHost == "" {
 		log.Error("BUILDKIT_HOST environment variable is not set")
@@ -64,37 +96,51 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 		return fmt.Errorf("failed to get buildkit info: %w", err)
 	}
 
-	buildPlatform := opts.Platform
-	if (buildPlatform == BuildPlatform{}) {
-		buildPlatform = DetermineBuildPlatformFromHost()
+	buildPlatforms := opts.Platforms
+	if len(buildPlatforms) == 0 {
+		buildPlatforms = []BuildPlatform{DetermineBuildPlatformFromHost()}
 	}
 
-	llbState, image, err := ConvertPlanToLLB(plan, ConvertPlanOptions{
-		BuildPlatform: buildPlatform,
-		SecretsHash:   opts.SecretsHash,
-		CacheKey:      opts.CacheKey,
-	})
-	if err != nil {
-		return fmt.Errorf("error converting plan to LLB: %w", err)
-	}
+	if opts.DumpLLB {
+		log.Info("Dumping LLB to stdout")
+		for _, buildPlatform := range buildPlatforms {
+			llbState, _, err := ConvertPlanToLLB(plan, ConvertPlanOptions{
+				BuildPlatform: buildPlatform,
+				SecretsHash:   opts.SecretsHash,
+				CacheKey:      opts.CacheKey,
+			})
+			if err != nil {
+				return fmt.Errorf("error converting plan to LLB: %w", err)
+			}
 
-	imageBytes, err := json.Marshal(image)
-	if err != nil {
-		return fmt.Errorf("error marshalling image: %w", err)
+			def, err := llbState.Marshal(ctx, llb.Platform(buildPlatform.ToPlatform()))
+			if err != nil {
+				return fmt.Errorf("error marshaling LLB state: %w", err)
+			}
+
+			err = llb.WriteTo(def, os.Stdout)
+			if err != nil {
+				return fmt.Errorf("error writing LLB definition: %w", err)
+			}
+		}
+		return nil
 	}
 
-	def, err := llbState.Marshal(ctx, llb.LinuxAmd64)
-	if err != nil {
-		return fmt.Errorf("error marshaling LLB state: %w", err)
+	// docker load only accepts a single platform image
+	if useDockerLoad && len(buildPlatforms) > 1 {
+		return fmt.Errorf("loading multi-platform images into Docker is not supported. Use --push to publish a manifest list or --output to save the filesystem for each platform")
 	}
 
-	if opts.DumpLLB {
-		log.Info("Dumping LLB to stdout")
-		err = llb.WriteTo(def, os.Stdout)
+	tarExporter := ""
+	if opts.OutputTar != "" {
+		tarExporter, err = getTarExporter(opts.OutputFormat)
 		if err != nil {
-			return fmt.Errorf("error writing LLB definition: %w", err)
+			return err
+		}
+
+		if tarExporter == client.ExporterDocker && len(buildPlatforms) > 1 {
+			return fmt.Errorf("docker tarballs do not support multi-platform images. Use the oci output format instead")
 		}
-		return nil
 	}
 
 	ch := make(chan *client.SolveStatus)
@@ -103,8 +149,8 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 	var pipeW *io.PipeWriter
 	errCh := make(chan error, 1)
 
-	// Only set up pipe and docker load if we're not saving to a directory
-	if opts.OutputDir == "" {
+	// Only set up pipe and docker load if we're not saving to a directory or pushing
+	if useDockerLoad {
 		// Create a pipe to connect buildkit output to docker load
 		pipeR, pipeW = io.Pipe()
 		defer pipeR.Close()
@@ -153,7 +199,11 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 		return fmt.Errorf("error creating FS: %w", err)
 	}
 
-	log.Debugf("Building image for %s with BuildKit %s", buildPlatform.String(), info.BuildkitVersion.Version)
+	platformNames := make([]string, 0, len(buildPlatforms))
+	for _, buildPlatform := range buildPlatforms {
+		platformNames = append(platformNames, buildPlatform.String())
+	}
+	log.Debugf("Building image for %s with BuildKit %s", strings.Join(platformNames, ", "), info.BuildkitVersion.Version)
 
 	secretsMap := make(map[string][]byte)
 	for k, v := range opts.Secrets {
@@ -170,8 +220,7 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 			{
 				Type: client.ExporterDocker,
 				Attrs: map[string]string{
-					"name":                  imageName,
-					"containerimage.config": string(imageBytes),
+					"name": imageName,
 				},
 				Output: func(_ map[string]string) (io.WriteCloser, error) {
 					return pipeW, nil
@@ -180,20 +229,66 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 		},
 	}
 
-	// Add cache import if specified
-	if opts.ImportCache != "" {
-		solveOpts.CacheImports = append(solveOpts.CacheImports, client.CacheOptionsEntry{
-			Type:  "gha",
-			Attrs: parseKeyValue(opts.ImportCache),
-		})
+	// Commands that use the host network need the entitlement, which also has to be allowed by the BuildKit daemon
+	if usesHostNetwork(plan) {
+		solveOpts.AllowedEntitlements = []entitlements.Entitlement{entitlements.EntitlementNetworkHost}
 	}
 
-	// Add cache export if specified
-	if opts.ExportCache != "" {
-		solveOpts.CacheExports = append(solveOpts.CacheExports, client.CacheOptionsEntry{
-			Type:  "gha",
-			Attrs: parseKeyValue(opts.ExportCache),
-		})
+	cacheImports, err := parseCacheOptions(opts.ImportCache, cacheImportTypes)
+	if err != nil {
+		return fmt.Errorf("invalid cache import: %w", err)
+	}
+	solveOpts.CacheImports = cacheImports
+
+	cacheExports, err := parseCacheOptions(opts.ExportCache, cacheExportTypes)
+	if err != nil {
+		return fmt.Errorf("invalid cache export: %w", err)
+	}
+	solveOpts.CacheExports = cacheExports
+
+	// Push the image to a registry using the credentials from the Docker config
+	if opts.Push {
+		dockerConfig := config.LoadDefaultConfigFile(os.Stderr)
+		solveOpts.Session = append(solveOpts.Session, authprovider.NewDockerAuthProvider(dockerConfig, nil))
+		solveOpts.Exports = []client.ExportEntry{
+			{
+				Type: client.ExporterImage,
+				Attrs: map[string]string{
+					"name": imageName,
+					"push": "true",
+				},
+			},
+		}
+	}
+
+	// Write the image as a tarball to a temporary file that is renamed once the build succeeds,
+	// so a failed build never leaves a partial tarball at the output path
+	var tarFile *os.File
+	if opts.OutputTar != "" {
+		if err := os.MkdirAll(filepath.Dir(opts.OutputTar), 0755); err != nil {
+			return fmt.Errorf("error creating output directory: %w", err)
+		}
+
+		tarFile, err = os.CreateTemp(filepath.Dir(opts.OutputTar), fmt.Sprintf(".%s.*.tmp", filepath.Base(opts.OutputTar)))
+		if err != nil {
+			return fmt.Errorf("error creating output tarball: %w", err)
+		}
+		defer func() {
+			tarFile.Close()
+			os.Remove(tarFile.Name())
+		}()
+
+		solveOpts.Exports = []client.ExportEntry{
+			{
+				Type: tarExporter,
+				Attrs: map[string]string{
+					"name": imageName,
+				},
+				Output: func(_ map[string]string) (io.WriteCloser, error) {
+					return tarFile, nil
+				},
+			},
+		}
 	}
 
 	// Save the resulting filesystem to a directory
@@ -203,21 +298,22 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 			return fmt.Errorf("error creating output directory: %w", err)
 		}
 
-		solveOpts = client.SolveOpt{
-			LocalMounts: map[string]fsutil.FS{
-				"context": appFS,
-			},
-			Exports: []client.ExportEntry{
-				{
-					Type:      client.ExporterLocal,
-					OutputDir: opts.OutputDir,
-				},
+		solveOpts.Exports = []client.ExportEntry{
+			{
+				Type:      client.ExporterLocal,
+				OutputDir: opts.OutputDir,
 			},
 		}
 	}
 
 	startTime := time.Now()
-	_, err = c.Solve(ctx, def, solveOpts, ch)
+	_, err = c.Build(ctx, solveOpts, "railpack", func(ctx context.Context, gw gateway.Client) (*gateway.Result, error) {
+		return SolvePlan(ctx, gw, plan, buildPlatforms, ConvertPlanOptions{
+			SecretsHash: opts.SecretsHash,
+			CacheKey:    opts.CacheKey,
+			SessionID:   gw.BuildOpts().SessionID,
+		})
+	}, ch)
 
 	// Wait for progress monitoring to complete
 	<-progressDone
@@ -231,17 +327,27 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 	}
 
 	// Only wait for docker load if we used it
-	if opts.OutputDir == "" {
+	if useDockerLoad {
 		if err := <-errCh; err != nil {
 			return fmt.Errorf("docker load failed: %w", err)
 		}
 	}
 
+	if tarFile != nil {
+		if err := saveOutputTar(tarFile, opts.OutputTar); err != nil {
+			return fmt.Errorf("error saving output tarball: %w", err)
+		}
+	}
+
 	buildDuration := time.Since(startTime)
 	log.Infof("Successfully built image in %.2fs", buildDuration.Seconds())
 
 	if opts.OutputDir != "" {
 		log.Infof("Saved image filesystem to directory `%s`", opts.OutputDir)
+	} else if opts.Push {
+		log.Infof("Pushed image `%s`", imageName)
+	} else if opts.OutputTar != "" {
+		log.Infof("Saved image tarball to `%s`", opts.OutputTar)
 	} else {
 		log.Infof("Run with `docker run -it %s`", imageName)
 	}
@@ -258,6 +364,53 @@ func getImageName(appDir string) string {
 	return name
 }
 
+// getTarExporter returns the BuildKit exporter for the tarball output format
+func getTarExporter(format string) (string, error) {
+	switch format {
+	case "", "oci":
+		return client.ExporterOCI, nil
+	case "docker":
+		return client.ExporterDocker, nil
+	default:
+		return "", fmt.Errorf("unsupported output format: %s. Must be one of: oci, docker", format)
+	}
+}
+
+// parseCacheOptions parses cache specs in the format type=local|registry|inline|s3|gha,key=value,...
+// Specs without a type default to the GitHub Actions cache
+func parseCacheOptions(specs []string, supportedTypes []string) ([]client.CacheOptionsEntry, error) {
+	entries := []client.CacheOptionsEntry{}
+
+	for _, spec := range specs {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+
+		attrs := parseKeyValue(spec)
+
+		cacheType := attrs["type"]
+		delete(attrs, "type")
+		if cacheType == "" {
+			cacheType = "gha"
+		}
+
+		if !slices.Contains(supportedTypes, cacheType) {
+			// An inline cache is stored in the image itself and is imported from the registry
+			if cacheType == "inline" {
+				return nil, fmt.Errorf("the inline cache can only be exported. Import it with type=registry,ref=<image>")
+			}
+			return nil, fmt.Errorf("unsupported cache type: %s. Must be one of: %s", cacheType, strings.Join(supportedTypes, ", "))
+		}
+
+		entries = append(entries, client.CacheOptionsEntry{
+			Type:  cacheType,
+			Attrs: attrs,
+		})
+	}
+
+	return entries, nil
+}
+
 // Helper function to parse key=value strings into a map
 func parseKeyValue(s string) map[string]string {
 	attrs := make(map[string]string)
@@ -270,3 +423,29 @@ func parseKeyValue(s string) map[string]string {
 	}
 	return attrs
 }
+
+func usesHostNetwork(buildPlan *plan.BuildPlan) bool {
+	for _, step := range buildPlan.Steps {
+		for _, cmd := range step.Commands {
+			if execCmd, ok := cmd.(plan.ExecCommand); ok && execCmd.Network == plan.NetworkModeHost {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// saveOutputTar moves the finished temporary tarball to the output path.
+// BuildKit closes the file once the export is done, so it may already be closed
+func saveOutputTar(tarFile *os.File, outputTar string) error {
+	if err := tarFile.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+		return err
+	}
+
+	// Temporary files are only readable by their owner, unlike a file created at the output path
+	if err := os.Chmod(tarFile.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tarFile.Name(), outputTar)
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/system"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/railwayapp/railpack/buildkit/graph"
@@ -73,13 +74,7 @@ func NewBuildGraph(plan *plan.BuildPlan, localState *llb.State, cacheStore *Buil
 
 			if depNode, exists := g.graph.GetNode(input.Step); exists {
 				// Create edges between the current node and the dependency node
-				parents := llbNode.GetParents()
-				parents = append(parents, depNode)
-				llbNode.SetParents(parents)
-
-				children := depNode.GetChildren()
-				children = append(children, llbNode)
-				depNode.SetChildren(children)
+				g.graph.AddEdge(depNode, llbNode)
 			}
 		}
 	}
@@ -108,7 +103,10 @@ func (g *BuildGraph) GenerateLLB() (*BuildGraphOutput, error) {
 	}
 
 	// Process deploy state
-	deployState := g.GetFullStateFromInputs(g.Plan.Deploy.Inputs)
+	deployState, err := g.getDeployState()
+	if err != nil {
+		return nil, err
+	}
 
 	graphEnv := NewGraphEnvironment()
 	for _, input := range g.Plan.Deploy.Inputs {
@@ -123,6 +121,37 @@ func (g *BuildGraph) GenerateLLB() (*BuildGraphOutput, error) {
 	}, nil
 }
 
+// getDeployState merges the deploy inputs into the final image state.
+// If the deploy runs as a non-root user, the user is created in the base image and all of /app and the copied inputs are owned by it
+func (g *BuildGraph) getDeployState() (llb.State, error) {
+	inputs := g.Plan.Deploy.Inputs
+	if g.Plan.Deploy.User == "" || len(inputs) == 0 {
+		return g.GetFullStateFromInputs(inputs), nil
+	}
+
+	user, err := plan.ParseUser(g.Plan.Deploy.User)
+	if err != nil {
+		return llb.Scratch(), err
+	}
+
+	if user.IsRoot() {
+		return g.GetFullStateFromInputs(inputs), nil
+	}
+
+	createUser := fmt.Sprintf("%s && mkdir -p /app && chown -R %s /app", user.CreateCommand("/app"), user.String())
+	base := g.GetStateForInput(inputs[0]).Run(
+		llb.Args([]string{"/bin/sh", "-c", createUser}),
+		llb.WithCustomName(fmt.Sprintf("[railpack] create user %s", user.Name)),
+	).Root()
+
+	owner := &llb.ChownOpt{
+		User:  &llb.UserOpt{UID: user.UID},
+		Group: &llb.UserOpt{UID: user.GID},
+	}
+
+	return g.mergeInputs(base, inputs, owner), nil
+}
+
 // processNode processes a node and its parents to determine the state to build upon
 func (g *BuildGraph) processNode(node *StepNode) error {
 	// If already processed, we're done
@@ -235,17 +264,38 @@ func (g *BuildGraph) convertCommandToLLB(node *StepNode, cmd plan.Command, state
 		return g.convertCopyCommandToLLB(cmd, state)
 	case plan.FileCommand:
 		return g.convertFileCommandToLLB(cmd, state, step)
+	case plan.VariableCommand:
+		return g.convertVariableCommandToLLB(node, cmd, state)
 	}
 	return state, nil
 }
 
 // convertExecCommandToLLB converts an exec command to an LLB state
 func (g *BuildGraph) convertExecCommandToLLB(node *StepNode, cmd plan.ExecCommand, state llb.State) (llb.State, error) {
-	opts := []llb.RunOption{llb.Shlex(cmd.Cmd)}
+	args, err := GetExecArgs(cmd)
+	if err != nil {
+		return state, err
+	}
+
+	opts := []llb.RunOption{llb.Args(args)}
 	if cmd.CustomName != "" {
 		opts = append(opts, llb.WithCustomName(cmd.CustomName))
 	}
 
+	// The directory, user, and network only apply to this command and not to the resulting state
+	if cmd.Dir != "" {
+		opts = append(opts, llb.Dir(cmd.Dir))
+	}
+	if cmd.User != "" {
+		opts = append(opts, llb.User(cmd.User))
+	}
+	switch cmd.Network {
+	case plan.NetworkModeNone:
+		opts = append(opts, llb.Network(pb.NetMode_NONE))
+	case plan.NetworkModeHost:
+		opts = append(opts, llb.Network(pb.NetMode_HOST))
+	}
+
 	if len(node.Step.Secrets) > 0 {
 		// These options mount all secrets as environments variables
 		secretOpts := []llb.RunOption{}
@@ -282,6 +332,17 @@ func (g *BuildGraph) convertPathCommandToLLB(node *StepNode, cmd plan.PathComman
 	return s, nil
 }
 
+// convertVariableCommandToLLB sets an environment variable for the rest of the step.
+// Global variables are also added to the output environment so they are available to later steps and the final image
+func (g *BuildGraph) convertVariableCommandToLLB(node *StepNode, cmd plan.VariableCommand, state llb.State) (llb.State, error) {
+	if cmd.Global {
+		node.OutputEnv.AddEnvVar(cmd.Name, cmd.Value)
+	}
+
+	s := state.AddEnv(cmd.Name, cmd.Value)
+	return s, nil
+}
+
 // convertCopyCommandToLLB converts a copy command to an LLB state
 func (g *BuildGraph) convertCopyCommandToLLB(cmd plan.CopyCommand, state llb.State) (llb.State, error) {
 	var src llb.State
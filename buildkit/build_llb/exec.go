@@ -0,0 +1,67 @@
+package build_llb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/shlex"
+	"github.com/railwayapp/railpack/core/plan"
+)
+
+// GetExecArgs returns the arguments used to run an exec command.
+// Commands with retries or a timeout are run by a small shell script that enforces them
+func GetExecArgs(cmd plan.ExecCommand) ([]string, error) {
+	args, err := shlex.Split(cmd.Cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse command %q: %w", cmd.Cmd, err)
+	}
+
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	if cmd.Retries == 0 && cmd.Timeout == "" {
+		return args, nil
+	}
+
+	durations, err := cmd.ParseDurations()
+	if err != nil {
+		return nil, err
+	}
+
+	run := `"$@"`
+	if durations.Timeout > 0 {
+		run = fmt.Sprintf(`timeout %s "$@"`, formatSeconds(durations.Timeout.Seconds()))
+	}
+
+	script := []string{
+		"attempt=0",
+		"while true; do",
+		fmt.Sprintf("  %s && exit 0", run),
+		"  status=$?",
+	}
+
+	if durations.Timeout > 0 {
+		script = append(script, fmt.Sprintf(`  [ "$status" -eq 124 ] && echo "railpack: command timed out after %s" >&2`, cmd.Timeout))
+	}
+
+	script = append(script,
+		"  attempt=$((attempt + 1))",
+		fmt.Sprintf(`  [ "$attempt" -gt %d ] && exit "$status"`, cmd.Retries),
+		fmt.Sprintf(`  echo "railpack: command failed with exit code $status, retrying ($attempt/%d)" >&2`, cmd.Retries),
+	)
+
+	if durations.RetryDelay > 0 {
+		script = append(script, fmt.Sprintf("  sleep %s", formatSeconds(durations.RetryDelay.Seconds())))
+	}
+
+	script = append(script, "done")
+
+	// The command is passed as arguments to the script so it does not need to be quoted again
+	return append([]string{"/bin/sh", "-c", strings.Join(script, "\n"), "sh"}, args...), nil
+}
+
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', -1, 64)
+}
@@ -0,0 +1,38 @@
+package build_llb
+
+import (
+	"testing"
+
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetExecArgs(t *testing.T) {
+	t.Run("plain command", func(t *testing.T) {
+		args, err := GetExecArgs(plan.ExecCommand{Cmd: "sh -c 'npm ci && npm run build'"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"sh", "-c", "npm ci && npm run build"}, args)
+	})
+
+	t.Run("retries and timeout", func(t *testing.T) {
+		args, err := GetExecArgs(plan.ExecCommand{Cmd: "npm ci", Retries: 2, RetryDelay: "5s", Timeout: "1m30s"})
+		require.NoError(t, err)
+
+		script := `attempt=0
+while true; do
+  timeout 90 "$@" && exit 0
+  status=$?
+  [ "$status" -eq 124 ] && echo "railpack: command timed out after 1m30s" >&2
+  attempt=$((attempt + 1))
+  [ "$attempt" -gt 2 ] && exit "$status"
+  echo "railpack: command failed with exit code $status, retrying ($attempt/2)" >&2
+  sleep 5
+done`
+		require.Equal(t, []string{"/bin/sh", "-c", script, "sh", "npm", "ci"}, args)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		_, err := GetExecArgs(plan.ExecCommand{Cmd: "npm ci", Retries: 2, RetryDelay: "soon"})
+		require.Error(t, err)
+	})
+}
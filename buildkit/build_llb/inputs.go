@@ -43,6 +43,13 @@ func (g *BuildGraph) GetFullStateFromInputs(inputs []plan.Input) llb.State {
 
 	// Get the base state from the first input
 	state := g.GetStateForInput(inputs[0])
+
+	return g.mergeInputs(state, inputs, nil)
+}
+
+// mergeInputs copies the includes of every input after the first on top of the base state.
+// If owner is set, the copied files are owned by that user
+func (g *BuildGraph) mergeInputs(state llb.State, inputs []plan.Input, owner *llb.ChownOpt) llb.State {
 	if len(inputs) == 1 {
 		return state
 	}
@@ -70,6 +77,7 @@ func (g *BuildGraph) GetFullStateFromInputs(inputs []plan.Input) llb.State {
 						AllowWildcard:       true,
 						AllowEmptyWildcard:  true,
 						ExcludePatterns:     input.Exclude,
+						ChownOpt:            owner,
 					}))
 				} else {
 					// For other states, handle paths based on whether they're absolute or relative
@@ -87,6 +95,7 @@ func (g *BuildGraph) GetFullStateFromInputs(inputs []plan.Input) llb.State {
 						AllowWildcard:       true,
 						AllowEmptyWildcard:  true,
 						ExcludePatterns:     input.Exclude,
+						ChownOpt:            owner,
 					}), opts...)
 				}
 			}
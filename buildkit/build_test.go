@@ -0,0 +1,115 @@
+package buildkit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/buildkit/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTarExporter(t *testing.T) {
+	exporter, err := getTarExporter("")
+	require.NoError(t, err)
+	require.Equal(t, client.ExporterOCI, exporter)
+
+	exporter, err = getTarExporter("docker")
+	require.NoError(t, err)
+	require.Equal(t, client.ExporterDocker, exporter)
+
+	_, err = getTarExporter("tar")
+	require.Error(t, err)
+}
+
+func TestParseCacheOptions(t *testing.T) {
+	tests := []struct {
+		name           string
+		specs          []string
+		supportedTypes []string
+		want           []client.CacheOptionsEntry
+		wantErr        string
+	}{
+		{
+			name: "local and registry",
+			specs: []string{
+				"type=local,dest=/tmp/cache,mode=max",
+				"type=registry,ref=localhost:5000/app:cache",
+			},
+			supportedTypes: cacheExportTypes,
+			want: []client.CacheOptionsEntry{
+				{Type: "local", Attrs: map[string]string{"dest": "/tmp/cache", "mode": "max"}},
+				{Type: "registry", Attrs: map[string]string{"ref": "localhost:5000/app:cache"}},
+			},
+		},
+		{
+			name:           "defaults to the gha type",
+			specs:          []string{"scope=main", "type=,scope=pr"},
+			supportedTypes: cacheImportTypes,
+			want: []client.CacheOptionsEntry{
+				{Type: "gha", Attrs: map[string]string{"scope": "main"}},
+				{Type: "gha", Attrs: map[string]string{"scope": "pr"}},
+			},
+		},
+		{
+			name:           "skips empty specs",
+			specs:          []string{"", "  "},
+			supportedTypes: cacheImportTypes,
+			want:           []client.CacheOptionsEntry{},
+		},
+		{
+			name:           "exports an inline cache",
+			specs:          []string{"type=inline"},
+			supportedTypes: cacheExportTypes,
+			want:           []client.CacheOptionsEntry{{Type: "inline", Attrs: map[string]string{}}},
+		},
+		{
+			name:           "rejects an inline cache import",
+			specs:          []string{"type=local,src=/tmp/cache", "type=inline"},
+			supportedTypes: cacheImportTypes,
+			wantErr:        "the inline cache can only be exported. Import it with type=registry,ref=<image>",
+		},
+		{
+			name:           "rejects unsupported types",
+			specs:          []string{"type=azblob,name=cache"},
+			supportedTypes: cacheImportTypes,
+			wantErr:        "unsupported cache type: azblob. Must be one of: local, registry, s3, gha",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := parseCacheOptions(tt.specs, tt.supportedTypes)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, entries)
+		})
+	}
+}
+
+func TestSaveOutputTar(t *testing.T) {
+	dir := t.TempDir()
+	outputTar := filepath.Join(dir, "image.tar")
+
+	tarFile, err := os.CreateTemp(dir, ".image.tar.*.tmp")
+	require.NoError(t, err)
+	_, err = tarFile.WriteString("image")
+	require.NoError(t, err)
+
+	// BuildKit closes the file after exporting, which must not fail the save
+	require.NoError(t, tarFile.Close())
+	require.NoError(t, saveOutputTar(tarFile, outputTar))
+
+	contents, err := os.ReadFile(outputTar)
+	require.NoError(t, err)
+	require.Equal(t, "image", string(contents))
+
+	// Only the output tarball is left
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -3,11 +3,15 @@ package buildkit
 import (
 	"fmt"
 	"maps"
+	"os"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/moby/buildkit/client/llb"
 	"github.com/moby/buildkit/util/system"
+	dockerspec "github.com/moby/docker-image-spec/specs-go/v1"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/railwayapp/railpack/buildkit/build_llb"
 	p "github.com/railwayapp/railpack/core/plan"
@@ -18,6 +22,9 @@ type ConvertPlanOptions struct {
 	SecretsHash   string
 	CacheKey      string
 	SessionID     string
+
+	// The creation time recorded on the image. Defaults to SOURCE_DATE_EPOCH if it is set and to now otherwise
+	Created time.Time
 }
 
 const (
@@ -48,13 +55,34 @@ func ConvertPlanToLLB(plan *p.BuildPlan, opts ConvertPlanOptions) (*llb.State, *
 	state := getStartState(*graphOutput.State)
 	imageEnv := getImageEnv(graphOutput, plan)
 
+	imageUser, err := getImageUser(plan)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exposedPorts, err := getExposedPorts(plan)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	healthcheck, err := getHealthcheck(plan)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	startCommand := plan.Deploy.StartCmd
 	if startCommand == "" {
 		startCommand = "/bin/bash"
 	}
 
+	created, err := getCreatedTime(opts.Created, os.Getenv("SOURCE_DATE_EPOCH"))
+	if err != nil {
+		return nil, nil, err
+	}
+
 	image := Image{
 		Image: specs.Image{
+			Created: &created,
 			Platform: specs.Platform{
 				OS:           platform.OS,
 				Architecture: platform.Architecture,
@@ -64,11 +92,20 @@ func ConvertPlanToLLB(plan *p.BuildPlan, opts ConvertPlanOptions) (*llb.State, *
 			},
 		},
 		Variant: platform.Variant,
-		Config: specs.ImageConfig{
-			Env:        imageEnv,
-			WorkingDir: WorkingDir,
-			Entrypoint: []string{"/bin/sh", "-c"},
-			Cmd:        []string{startCommand},
+		Config: dockerspec.DockerOCIImageConfig{
+			ImageConfig: specs.ImageConfig{
+				User:         imageUser,
+				ExposedPorts: exposedPorts,
+				Env:          imageEnv,
+				WorkingDir:   WorkingDir,
+				Entrypoint:   []string{"/bin/sh", "-c"},
+				Cmd:          []string{startCommand},
+				Labels:       getImageLabels(plan, created),
+				StopSignal:   plan.Deploy.StopSignal,
+			},
+			DockerOCIImageConfigExt: dockerspec.DockerOCIImageConfigExt{
+				Healthcheck: healthcheck,
+			},
 		},
 	}
 
@@ -80,6 +117,95 @@ func getStartState(buildState llb.State) llb.State {
 	return startState
 }
 
+// getExposedPorts returns the deploy ports in the port/protocol format of the image config
+func getExposedPorts(plan *p.BuildPlan) (map[string]struct{}, error) {
+	if len(plan.Deploy.Ports) == 0 {
+		return nil, nil
+	}
+
+	exposedPorts := make(map[string]struct{}, len(plan.Deploy.Ports))
+	for _, port := range plan.Deploy.Ports {
+		exposedPort, err := p.ParsePort(port)
+		if err != nil {
+			return nil, err
+		}
+		exposedPorts[exposedPort] = struct{}{}
+	}
+
+	return exposedPorts, nil
+}
+
+// getHealthcheck converts the deploy healthcheck to a shell healthcheck for the image config
+func getHealthcheck(plan *p.BuildPlan) (*dockerspec.HealthcheckConfig, error) {
+	healthcheck := plan.Deploy.Healthcheck
+	if healthcheck == nil {
+		return nil, nil
+	}
+
+	if err := healthcheck.Validate(); err != nil {
+		return nil, err
+	}
+
+	durations, err := healthcheck.ParseDurations()
+	if err != nil {
+		return nil, err
+	}
+
+	return &dockerspec.HealthcheckConfig{
+		Test:        []string{"CMD-SHELL", healthcheck.Cmd},
+		Interval:    durations.Interval,
+		Timeout:     durations.Timeout,
+		StartPeriod: durations.StartPeriod,
+		Retries:     healthcheck.Retries,
+	}, nil
+}
+
+// getImageUser returns the numeric uid:gid the container runs as, or an empty string for root
+func getImageUser(plan *p.BuildPlan) (string, error) {
+	if plan.Deploy.User == "" {
+		return "", nil
+	}
+
+	user, err := p.ParseUser(plan.Deploy.User)
+	if err != nil {
+		return "", err
+	}
+
+	if user.IsRoot() {
+		return "", nil
+	}
+
+	return user.String(), nil
+}
+
+// getCreatedTime returns the creation time of an image. Reproducible builds set SOURCE_DATE_EPOCH to a fixed number of seconds
+// since the Unix epoch (https://reproducible-builds.org/specs/source-date-epoch/), which is used instead of the current time
+func getCreatedTime(created time.Time, sourceDateEpoch string) (time.Time, error) {
+	if !created.IsZero() {
+		return created, nil
+	}
+
+	if sourceDateEpoch == "" {
+		return time.Now().UTC(), nil
+	}
+
+	seconds, err := strconv.ParseInt(sourceDateEpoch, 10, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH `%s`: must be a non-negative number of seconds since the Unix epoch", sourceDateEpoch)
+	}
+
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// getImageLabels returns the plan's image labels along with the image creation time
+func getImageLabels(plan *p.BuildPlan, created time.Time) map[string]string {
+	labels := plan.Deploy.ImageLabels()
+	if _, ok := labels[p.LabelCreated]; !ok {
+		labels[p.LabelCreated] = created.Format(time.RFC3339)
+	}
+	return labels
+}
+
 func getImageEnv(graphOutput *build_llb.BuildGraphOutput, plan *p.BuildPlan) []string {
 	paths := []string{}
 	paths = append(paths, plan.Deploy.Paths...)
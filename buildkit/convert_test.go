@@ -0,0 +1,27 @@
+package buildkit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCreatedTime(t *testing.T) {
+	created, err := getCreatedTime(time.Time{}, "1700000000")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC), created)
+
+	// An explicit creation time takes precedence over SOURCE_DATE_EPOCH
+	explicit := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	created, err = getCreatedTime(explicit, "1700000000")
+	require.NoError(t, err)
+	require.Equal(t, explicit, created)
+
+	created, err = getCreatedTime(time.Time{}, "")
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), created, time.Minute)
+
+	_, err = getCreatedTime(time.Time{}, "yesterday")
+	require.ErrorContains(t, err, "invalid SOURCE_DATE_EPOCH `yesterday`")
+}
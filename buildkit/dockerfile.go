@@ -0,0 +1,548 @@
+package buildkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/moby/buildkit/util/system"
+	"github.com/railwayapp/railpack/buildkit/build_llb"
+	p "github.com/railwayapp/railpack/core/plan"
+)
+
+const (
+	dockerfileSyntax     = "docker/dockerfile:1"
+	dockerfileLabsSyntax = "docker/dockerfile:1-labs"
+)
+
+type ConvertPlanToDockerfileOptions struct {
+	CacheKey string
+
+	// The builder and runtime images the plan was generated with. They run as root like the default Railpack images
+	BuilderImage string
+	RuntimeImage string
+}
+
+type dockerfileEnv struct {
+	pathList []string
+	envVars  map[string]string
+}
+
+type dockerfileConverter struct {
+	plan    *p.BuildPlan
+	opts    ConvertPlanToDockerfileOptions
+	stages  map[string]string
+	envs    map[string]*dockerfileEnv
+	order   []*p.Step
+	useLabs bool
+
+	// The user the commands of each stage run as. It is empty while a stage runs as the unknown default user of its base image
+	users map[string]string
+}
+
+var invalidStageNameChars = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+// ConvertPlanToDockerfile converts a build plan into an equivalent multi-stage Dockerfile
+// Each step becomes a named stage and the deploy section becomes the final stage
+func ConvertPlanToDockerfile(plan *p.BuildPlan, opts ConvertPlanToDockerfileOptions) (string, error) {
+	if opts.BuilderImage == "" {
+		opts.BuilderImage = p.RAILPACK_BUILDER_IMAGE
+	}
+	if opts.RuntimeImage == "" {
+		opts.RuntimeImage = p.RAILPACK_RUNTIME_IMAGE
+	}
+
+	c := &dockerfileConverter{
+		plan:   plan,
+		opts:   opts,
+		stages: make(map[string]string),
+		envs:   make(map[string]*dockerfileEnv),
+		users:  make(map[string]string),
+	}
+
+	usedStageNames := make(map[string]bool)
+	for _, step := range plan.Steps {
+		stageName := getStageName(step.Name)
+		for i := 2; usedStageNames[stageName]; i++ {
+			stageName = fmt.Sprintf("%s-%d", getStageName(step.Name), i)
+		}
+
+		usedStageNames[stageName] = true
+		c.stages[step.Name] = stageName
+	}
+
+	if err := c.computeOrder(); err != nil {
+		return "", err
+	}
+
+	var body strings.Builder
+	for _, step := range c.order {
+		if err := c.writeStep(&body, step); err != nil {
+			return "", err
+		}
+		body.WriteString("\n")
+	}
+
+	if err := c.writeDeploy(&body); err != nil {
+		return "", err
+	}
+
+	syntax := dockerfileSyntax
+	if c.useLabs {
+		syntax = dockerfileLabsSyntax
+	}
+
+	return fmt.Sprintf("# syntax=%s\n\n%s", syntax, body.String()), nil
+}
+
+// computeOrder sorts the steps so that every stage is defined before it is referenced
+func (c *dockerfileConverter) computeOrder() error {
+	visited := make(map[string]bool)
+	inProgress := make(map[string]bool)
+
+	stepsByName := make(map[string]*p.Step, len(c.plan.Steps))
+	for i := range c.plan.Steps {
+		stepsByName[c.plan.Steps[i].Name] = &c.plan.Steps[i]
+	}
+
+	var visit func(step *p.Step) error
+	visit = func(step *p.Step) error {
+		if visited[step.Name] {
+			return nil
+		}
+		if inProgress[step.Name] {
+			return fmt.Errorf("cycle detected: %s", step.Name)
+		}
+		inProgress[step.Name] = true
+
+		for _, input := range step.Inputs {
+			if input.Step == "" {
+				continue
+			}
+
+			parent, ok := stepsByName[input.Step]
+			if !ok {
+				return fmt.Errorf("step %s references unknown step %s", step.Name, input.Step)
+			}
+
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+
+		delete(inProgress, step.Name)
+		visited[step.Name] = true
+		c.order = append(c.order, step)
+		return nil
+	}
+
+	for i := range c.plan.Steps {
+		if err := visit(&c.plan.Steps[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *dockerfileConverter) writeStep(out *strings.Builder, step *p.Step) error {
+	if len(step.Inputs) == 0 {
+		return fmt.Errorf("step %s has no inputs", step.Name)
+	}
+
+	fmt.Fprintf(out, "# %s\n", step.Name)
+
+	base, err := c.getFromReference(step.Inputs[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "FROM %s AS %s\n", base, c.stages[step.Name])
+	c.users[step.Name] = c.getBaseUser(step.Inputs[0])
+
+	if err := c.writeInputCopies(out, step.Inputs[1:], ""); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "WORKDIR %s\n", WorkingDir)
+
+	env := c.getInputEnv(step.Inputs)
+	maps.Copy(env.envVars, step.Variables)
+	c.envs[step.Name] = env
+
+	for _, k := range slices.Sorted(maps.Keys(env.envVars)) {
+		fmt.Fprintf(out, "ENV %s=%s\n", k, quoteEnvValue(env.envVars[k]))
+	}
+	if len(env.pathList) > 0 {
+		writePathEnv(out, env.pathList)
+	}
+
+	for _, cmd := range step.Commands {
+		if err := c.writeCommand(out, step, env, cmd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *dockerfileConverter) writeDeploy(out *strings.Builder) error {
+	deploy := c.plan.Deploy
+	if len(deploy.Inputs) == 0 {
+		return fmt.Errorf("deploy has no inputs")
+	}
+
+	out.WriteString("# deploy\n")
+
+	base, err := c.getFromReference(deploy.Inputs[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "FROM %s\n", base)
+
+	imageUser := ""
+	if deploy.User != "" {
+		user, err := p.ParseUser(deploy.User)
+		if err != nil {
+			return err
+		}
+
+		if !user.IsRoot() {
+			imageUser = user.String()
+			createUser := fmt.Sprintf("%s && mkdir -p %s && chown -R %s %s", user.CreateCommand(WorkingDir), WorkingDir, imageUser, WorkingDir)
+			fmt.Fprintf(out, "RUN %s\n", jsonArray([]string{"/bin/sh", "-c", createUser}))
+		}
+	}
+
+	if err := c.writeInputCopies(out, deploy.Inputs[1:], imageUser); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "WORKDIR %s\n", WorkingDir)
+
+	env := c.getInputEnv(deploy.Inputs)
+	maps.Copy(env.envVars, deploy.Variables)
+	for _, k := range slices.Sorted(maps.Keys(env.envVars)) {
+		fmt.Fprintf(out, "ENV %s=%s\n", k, quoteEnvValue(env.envVars[k]))
+	}
+
+	paths := []string{}
+	paths = append(paths, deploy.Paths...)
+	paths = append(paths, env.pathList...)
+	paths = append(paths, system.DefaultPathEnvUnix)
+	slices.Sort(paths)
+	fmt.Fprintf(out, "ENV PATH=%s\n", quoteEnvValue(strings.Join(paths, ":")))
+
+	labels := deploy.ImageLabels()
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		fmt.Fprintf(out, "LABEL %s=%s\n", k, quoteEnvValue(labels[k]))
+	}
+
+	startCommand := deploy.StartCmd
+	if startCommand == "" {
+		startCommand = "/bin/bash"
+	}
+
+	for _, port := range deploy.Ports {
+		exposedPort, err := p.ParsePort(port)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "EXPOSE %s\n", exposedPort)
+	}
+
+	if deploy.Healthcheck != nil {
+		if err := writeHealthcheck(out, deploy.Healthcheck); err != nil {
+			return err
+		}
+	}
+
+	if deploy.StopSignal != "" {
+		fmt.Fprintf(out, "STOPSIGNAL %s\n", deploy.StopSignal)
+	}
+
+	if imageUser != "" {
+		fmt.Fprintf(out, "USER %s\n", imageUser)
+	}
+
+	fmt.Fprintf(out, "ENTRYPOINT %s\n", jsonArray([]string{"/bin/sh", "-c"}))
+	fmt.Fprintf(out, "CMD %s\n", jsonArray([]string{startCommand}))
+
+	return nil
+}
+
+func writeHealthcheck(out *strings.Builder, healthcheck *p.Healthcheck) error {
+	if err := healthcheck.Validate(); err != nil {
+		return err
+	}
+
+	flags := []string{}
+	if healthcheck.Interval != "" {
+		flags = append(flags, "--interval="+healthcheck.Interval)
+	}
+	if healthcheck.Timeout != "" {
+		flags = append(flags, "--timeout="+healthcheck.Timeout)
+	}
+	if healthcheck.StartPeriod != "" {
+		flags = append(flags, "--start-period="+healthcheck.StartPeriod)
+	}
+	if healthcheck.Retries > 0 {
+		flags = append(flags, fmt.Sprintf("--retries=%d", healthcheck.Retries))
+	}
+
+	fmt.Fprintf(out, "HEALTHCHECK %s\n", strings.Join(append(flags, "CMD", jsonArray([]string{"/bin/sh", "-c", healthcheck.Cmd})), " "))
+	return nil
+}
+
+func (c *dockerfileConverter) getFromReference(input p.Input) (string, error) {
+	if input.Image != "" {
+		return input.Image, nil
+	}
+
+	if input.Step != "" {
+		stage, ok := c.stages[input.Step]
+		if !ok {
+			return "", fmt.Errorf("unknown step %s", input.Step)
+		}
+		return stage, nil
+	}
+
+	return "", fmt.Errorf("the first input must be an image or step input, got %s", input.DisplayName())
+}
+
+// getBaseUser returns the user a stage starts with. Stages inherit the user of the stage they are built on,
+// while the default user of an image is only known for the builder and runtime images, which run as root
+func (c *dockerfileConverter) getBaseUser(input p.Input) string {
+	if input.Step != "" {
+		return c.users[input.Step]
+	}
+
+	if input.Image == c.opts.BuilderImage || input.Image == c.opts.RuntimeImage {
+		return "root"
+	}
+
+	return ""
+}
+
+// writeInputCopies copies the included paths of every additional input into the current stage.
+// Like in the BuildKit build, inputs without include paths are not copied
+func (c *dockerfileConverter) writeInputCopies(out *strings.Builder, inputs []p.Input, chown string) error {
+	for _, input := range inputs {
+		if len(input.Include) == 0 {
+			log.Warnf("input %s has no include paths and is not copied. This is probably a mistake.", input.DisplayName())
+			fmt.Fprintf(out, "# Input %s is not copied because it has no include paths\n", input.DisplayName())
+			continue
+		}
+
+		flags := []string{}
+		if chown != "" {
+			flags = append(flags, "--chown="+chown)
+		}
+		if !input.Local {
+			from, err := c.getFromReference(input)
+			if err != nil {
+				return err
+			}
+			flags = append(flags, "--from="+from)
+		}
+
+		for _, exclude := range input.Exclude {
+			c.useLabs = true
+			flags = append(flags, "--exclude="+exclude)
+		}
+
+		for _, include := range input.Include {
+			var srcPath, destPath string
+			if input.Local {
+				// For local context, always copy into /app
+				srcPath, destPath = include, filepath.Join(WorkingDir, filepath.Base(include))
+			} else {
+				srcPath, destPath = resolveDockerfilePaths(include)
+			}
+
+			fmt.Fprintf(out, "COPY %s\n", strings.Join(append(slices.Clone(flags), srcPath, destPath), " "))
+		}
+	}
+
+	return nil
+}
+
+func (c *dockerfileConverter) writeCommand(out *strings.Builder, step *p.Step, env *dockerfileEnv, cmd p.Command) error {
+	switch cmd := cmd.(type) {
+	case p.ExecCommand:
+		return c.writeExecCommand(out, step, cmd)
+	case p.PathCommand:
+		if !slices.Contains(env.pathList, cmd.Path) {
+			env.pathList = append([]string{cmd.Path}, env.pathList...)
+		}
+		writePathEnv(out, env.pathList)
+	case p.CopyCommand:
+		if cmd.Image != "" {
+			fmt.Fprintf(out, "COPY --from=%s %s %s\n", cmd.Image, cmd.Src, cmd.Dest)
+		} else {
+			fmt.Fprintf(out, "COPY %s %s\n", cmd.Src, cmd.Dest)
+		}
+	case p.FileCommand:
+		return c.writeFileCommand(out, step, cmd)
+	case p.VariableCommand:
+		if cmd.Global {
+			env.envVars[cmd.Name] = cmd.Value
+		}
+		fmt.Fprintf(out, "ENV %s=%s\n", cmd.Name, quoteEnvValue(cmd.Value))
+	}
+
+	return nil
+}
+
+func (c *dockerfileConverter) writeExecCommand(out *strings.Builder, step *p.Step, cmd p.ExecCommand) error {
+	args, err := build_llb.GetExecArgs(cmd)
+	if err != nil {
+		return err
+	}
+
+	flags := []string{}
+
+	for _, cacheKey := range step.Caches {
+		planCache, ok := c.plan.Caches[cacheKey]
+		if !ok {
+			return fmt.Errorf("cache with key %q not found", cacheKey)
+		}
+
+		id := cacheKey
+		if c.opts.CacheKey != "" {
+			id = fmt.Sprintf("%s-%s", c.opts.CacheKey, cacheKey)
+		}
+
+		sharing := "shared"
+		if planCache.Type == p.CacheTypeLocked {
+			sharing = "locked"
+		}
+
+		flags = append(flags, fmt.Sprintf("--mount=type=cache,id=%s,target=%s,sharing=%s", id, planCache.Directory, sharing))
+	}
+
+	// Mirror the LLB conversion and mount every plan secret when the step uses secrets
+	if len(step.Secrets) > 0 {
+		for _, secret := range c.plan.Secrets {
+			flags = append(flags, fmt.Sprintf("--mount=type=secret,id=%s,env=%s", secret, secret))
+		}
+	}
+
+	if cmd.Network == p.NetworkModeNone || cmd.Network == p.NetworkModeHost {
+		flags = append(flags, fmt.Sprintf("--network=%s", cmd.Network))
+	}
+
+	if cmd.CustomName != "" {
+		fmt.Fprintf(out, "# %s\n", strings.ReplaceAll(cmd.CustomName, "\n", " "))
+	}
+
+	// Dockerfiles can't set the directory or user of a single RUN instruction,
+	// so they are set around it and restored to the ones of the stage afterwards
+	if cmd.Dir != "" {
+		fmt.Fprintf(out, "WORKDIR %s\n", cmd.Dir)
+	}
+	if cmd.User != "" {
+		fmt.Fprintf(out, "USER %s\n", cmd.User)
+	}
+
+	fmt.Fprintf(out, "RUN %s\n", strings.Join(append(flags, jsonArray(args)), " \\\n    "))
+
+	if cmd.User != "" {
+		user := c.users[step.Name]
+		if user == "" {
+			// Commands run as root in the BuildKit build, which is the closest match for the user that can't be restored
+			fmt.Fprintln(out, "# The default user of the base image is unknown, so the following commands run as root")
+			user = "root"
+			c.users[step.Name] = user
+		}
+		fmt.Fprintf(out, "USER %s\n", user)
+	}
+	if cmd.Dir != "" {
+		fmt.Fprintln(out, "WORKDIR /app")
+	}
+
+	return nil
+}
+
+func (c *dockerfileConverter) writeFileCommand(out *strings.Builder, step *p.Step, cmd p.FileCommand) error {
+	asset, ok := step.Assets[cmd.Name]
+	if !ok {
+		return fmt.Errorf("asset %q not found", cmd.Name)
+	}
+
+	delimiter := "RAILPACK_EOF"
+	for i := 1; strings.Contains(asset, delimiter); i++ {
+		delimiter = fmt.Sprintf("RAILPACK_EOF_%d", i)
+	}
+
+	flags := ""
+	if cmd.Mode != 0 {
+		flags = fmt.Sprintf("--chmod=%o ", cmd.Mode)
+	}
+
+	if !strings.HasSuffix(asset, "\n") {
+		asset += "\n"
+	}
+
+	fmt.Fprintf(out, "COPY %s<<'%s' %s\n%s%s\n", flags, delimiter, cmd.Path, asset, delimiter)
+
+	return nil
+}
+
+// getInputEnv merges the environments of all the step inputs
+func (c *dockerfileConverter) getInputEnv(inputs []p.Input) *dockerfileEnv {
+	env := &dockerfileEnv{
+		pathList: []string{},
+		envVars:  make(map[string]string),
+	}
+
+	for _, input := range inputs {
+		if parentEnv, ok := c.envs[input.Step]; ok {
+			env.pathList = append(env.pathList, parentEnv.pathList...)
+			maps.Copy(env.envVars, parentEnv.envVars)
+		}
+	}
+
+	env.pathList = slices.Compact(env.pathList)
+
+	return env
+}
+
+func resolveDockerfilePaths(include string) (srcPath, destPath string) {
+	switch {
+	case include == "." || include == WorkingDir || include == WorkingDir+"/":
+		return WorkingDir, WorkingDir
+	case filepath.IsAbs(include):
+		return include, include
+	default:
+		return filepath.Join(WorkingDir, include), filepath.Join(WorkingDir, include)
+	}
+}
+
+func writePathEnv(out *strings.Builder, paths []string) {
+	pathString := strings.Join(append(slices.Clone(paths), system.DefaultPathEnvUnix), ":")
+	fmt.Fprintf(out, "ENV PATH=%s\n", quoteEnvValue(pathString))
+}
+
+func getStageName(stepName string) string {
+	name := invalidStageNameChars.ReplaceAllString(strings.ToLower(stepName), "-")
+	return strings.Trim(name, "-")
+}
+
+func quoteEnvValue(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`).Replace(value)
+	return fmt.Sprintf("\"%s\"", escaped)
+}
+
+func jsonArray(values []string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(values)
+	return strings.TrimSpace(buf.String())
+}
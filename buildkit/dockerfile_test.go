@@ -0,0 +1,213 @@
+package buildkit
+
+import (
+	"testing"
+
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertPlanToDockerfile(t *testing.T) {
+	buildPlan := plan.NewBuildPlan()
+	buildPlan.Caches["npm"] = plan.NewCache("/root/.npm")
+	buildPlan.Secrets = []string{"NPM_TOKEN"}
+
+	buildStep := plan.NewStep("build")
+	buildStep.Inputs = []plan.Input{
+		plan.NewStepInput("packages:mise"),
+		plan.NewLocalInput("."),
+	}
+	buildStep.Commands = []plan.Command{
+		plan.NewExecShellCommand("npm ci && npm run build"),
+		plan.NewFileCommand("/app/.npmrc", "npmrc", plan.FileOptions{Mode: 0600}),
+	}
+	buildStep.Caches = []string{"npm"}
+	buildStep.Assets["npmrc"] = "registry=https://registry.npmjs.org/"
+	buildStep.Variables["NODE_ENV"] = "production"
+
+	miseStep := plan.NewStep("packages:mise")
+	miseStep.Inputs = []plan.Input{plan.NewImageInput(plan.RAILPACK_BUILDER_IMAGE)}
+	miseStep.Commands = []plan.Command{plan.NewPathCommand("/mise/shims")}
+	miseStep.Secrets = []string{}
+
+	// Steps are intentionally out of order to verify the stages are sorted
+	buildPlan.AddStep(*buildStep)
+	buildPlan.AddStep(*miseStep)
+
+	buildPlan.Deploy = plan.Deploy{
+		Inputs: []plan.Input{
+			plan.NewImageInput(plan.RAILPACK_RUNTIME_IMAGE),
+			plan.NewStepInput("build", plan.InputOptions{Include: []string{"."}, Exclude: []string{"node_modules"}}),
+		},
+		StartCmd:  "npm start",
+		User:      "railpack",
+		Processes: map[string]string{"worker": "npm run worker"},
+		Ports:     []string{"3000"},
+		Healthcheck: &plan.Healthcheck{
+			Cmd:      "curl -f http://localhost:3000/",
+			Interval: "30s",
+			Retries:  3,
+		},
+		StopSignal: "SIGINT",
+		Labels:     map[string]string{"com.railpack.provider": "node"},
+	}
+
+	dockerfile, err := ConvertPlanToDockerfile(buildPlan, ConvertPlanToDockerfileOptions{CacheKey: "app"})
+	require.NoError(t, err)
+
+	expected := `# syntax=docker/dockerfile:1-labs
+
+# packages:mise
+FROM ghcr.io/railwayapp/railpack-builder:latest AS packages-mise
+WORKDIR /app
+ENV PATH="/mise/shims:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+# build
+FROM packages-mise AS build
+COPY . /app
+WORKDIR /app
+ENV NODE_ENV="production"
+ENV PATH="/mise/shims:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+# npm ci && npm run build
+RUN --mount=type=cache,id=app-npm,target=/root/.npm,sharing=shared \
+    --mount=type=secret,id=NPM_TOKEN,env=NPM_TOKEN \
+    ["sh","-c","npm ci && npm run build"]
+COPY --chmod=600 <<'RAILPACK_EOF' /app/.npmrc
+registry=https://registry.npmjs.org/
+RAILPACK_EOF
+
+# deploy
+FROM ghcr.io/railwayapp/railpack-runtime:latest
+RUN ["/bin/sh","-c","(getent group railpack >/dev/null || getent group 1000 >/dev/null || groupadd --gid 1000 railpack) && (getent passwd railpack >/dev/null || getent passwd 1000 >/dev/null || useradd --uid 1000 --gid 1000 --home-dir /app --no-create-home --shell /bin/sh railpack) && (! getent passwd railpack >/dev/null || [ \"$(id -u railpack)\" = \"1000\" ] || { echo \"railpack: user railpack already exists with uid $(id -u railpack). Set the deploy user to its uid:gid instead\" >&2; exit 1; }) && mkdir -p /app && chown -R 1000:1000 /app"]
+COPY --chown=1000:1000 --from=build --exclude=node_modules /app /app
+WORKDIR /app
+ENV NODE_ENV="production"
+ENV PATH="/mise/shims:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+LABEL com.railpack.process.worker="npm run worker"
+LABEL com.railpack.provider="node"
+EXPOSE 3000/tcp
+HEALTHCHECK --interval=30s --retries=3 CMD ["/bin/sh","-c","curl -f http://localhost:3000/"]
+STOPSIGNAL SIGINT
+USER 1000:1000
+ENTRYPOINT ["/bin/sh","-c"]
+CMD ["npm start"]
+`
+
+	require.Equal(t, expected, dockerfile)
+}
+
+func TestConvertPlanToDockerfileExecOptions(t *testing.T) {
+	buildPlan := plan.NewBuildPlan()
+
+	step := plan.NewStep("build")
+	step.Inputs = []plan.Input{plan.NewImageInput(plan.RAILPACK_BUILDER_IMAGE)}
+	step.Commands = []plan.Command{
+		plan.NewExecCommand("npm test", plan.ExecOptions{Dir: "packages/api", User: "node", Network: plan.NetworkModeNone}),
+	}
+	step.Secrets = []string{}
+	buildPlan.AddStep(*step)
+
+	buildPlan.Deploy = plan.Deploy{
+		Inputs: []plan.Input{plan.NewStepInput("build")},
+	}
+
+	dockerfile, err := ConvertPlanToDockerfile(buildPlan, ConvertPlanToDockerfileOptions{})
+	require.NoError(t, err)
+	require.Contains(t, dockerfile, `WORKDIR packages/api
+USER node
+RUN --network=none \
+    ["npm","test"]
+USER root
+WORKDIR /app
+`)
+
+	// The default user of other images is unknown, so the user is reset to root like in the BuildKit build
+	buildPlan.Steps[0].Inputs = []plan.Input{plan.NewImageInput("node:22")}
+	buildPlan.Steps[0].Commands = append(buildPlan.Steps[0].Commands, plan.NewExecCommand("npm run lint", plan.ExecOptions{User: "node"}))
+
+	dockerfile, err = ConvertPlanToDockerfile(buildPlan, ConvertPlanToDockerfileOptions{})
+	require.NoError(t, err)
+	require.Contains(t, dockerfile, `USER node
+RUN --network=none \
+    ["npm","test"]
+# The default user of the base image is unknown, so the following commands run as root
+USER root
+WORKDIR /app
+USER node
+RUN ["npm","run","lint"]
+USER root
+`)
+
+	// A configured builder image runs as root like the default one
+	dockerfile, err = ConvertPlanToDockerfile(buildPlan, ConvertPlanToDockerfileOptions{BuilderImage: "node:22"})
+	require.NoError(t, err)
+	require.NotContains(t, dockerfile, "The default user of the base image is unknown")
+}
+
+func TestConvertPlanToDockerfileVariableCommands(t *testing.T) {
+	buildPlan := plan.NewBuildPlan()
+
+	step := plan.NewStep("build")
+	step.Inputs = []plan.Input{plan.NewImageInput(plan.RAILPACK_BUILDER_IMAGE)}
+	step.Commands = []plan.Command{
+		plan.NewVariableCommand("JAVA_HOME", "/opt/java"),
+		plan.NewVariableCommand("BUILD_ID", "abc 123", plan.VariableOptions{Global: true}),
+	}
+	step.Secrets = []string{}
+	buildPlan.AddStep(*step)
+
+	buildPlan.Deploy = plan.Deploy{
+		Inputs: []plan.Input{
+			plan.NewImageInput(plan.RAILPACK_RUNTIME_IMAGE),
+			plan.NewStepInput("build", plan.InputOptions{Include: []string{"."}}),
+		},
+	}
+
+	dockerfile, err := ConvertPlanToDockerfile(buildPlan, ConvertPlanToDockerfileOptions{})
+	require.NoError(t, err)
+
+	// Only the global variable is passed on to the final image
+	require.Contains(t, dockerfile, `ENV JAVA_HOME="/opt/java"
+ENV BUILD_ID="abc 123"
+
+# deploy
+FROM ghcr.io/railwayapp/railpack-runtime:latest
+COPY --from=build /app /app
+WORKDIR /app
+ENV BUILD_ID="abc 123"
+`)
+}
+
+func TestConvertPlanToDockerfileUnknownStep(t *testing.T) {
+	buildPlan := plan.NewBuildPlan()
+
+	buildStep := plan.NewStep("build")
+	buildStep.Inputs = []plan.Input{plan.NewStepInput("missing")}
+	buildPlan.AddStep(*buildStep)
+
+	_, err := ConvertPlanToDockerfile(buildPlan, ConvertPlanToDockerfileOptions{})
+	require.Error(t, err)
+}
+
+func TestConvertPlanToDockerfileInputWithoutInclude(t *testing.T) {
+	buildPlan := plan.NewBuildPlan()
+
+	step := plan.NewStep("build")
+	step.Inputs = []plan.Input{plan.NewImageInput(plan.RAILPACK_BUILDER_IMAGE)}
+	step.Secrets = []string{}
+	buildPlan.AddStep(*step)
+
+	buildPlan.Deploy = plan.Deploy{
+		Inputs: []plan.Input{
+			plan.NewImageInput(plan.RAILPACK_RUNTIME_IMAGE),
+			plan.NewStepInput("build"),
+		},
+	}
+
+	dockerfile, err := ConvertPlanToDockerfile(buildPlan, ConvertPlanToDockerfileOptions{})
+	require.NoError(t, err)
+	require.Contains(t, dockerfile, `FROM ghcr.io/railwayapp/railpack-runtime:latest
+# Input $build is not copied because it has no include paths
+WORKDIR /app
+`)
+}
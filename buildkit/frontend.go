@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/moby/buildkit/client/llb"
@@ -15,6 +18,7 @@ import (
 	"github.com/moby/buildkit/util/appcontext"
 	"github.com/pkg/errors"
 	"github.com/railwayapp/railpack/core/plan"
+	"github.com/railwayapp/railpack/internal/utils"
 )
 
 const (
@@ -47,8 +51,7 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 	cacheKey := buildArgs[cacheKey]
 	secretsHash := buildArgs[secretsHash]
 
-	// TODO: Support building for multiple platforms
-	buildPlatform, err := validatePlatform(opts)
+	buildPlatforms, err := validatePlatforms(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -63,36 +66,115 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 		return nil, fmt.Errorf("error marshalling plan: %w", err)
 	}
 
-	llbState, image, err := ConvertPlanToLLB(plan, ConvertPlanOptions{
-		BuildPlatform: buildPlatform,
-		SecretsHash:   secretsHash,
-		CacheKey:      cacheKey,
-		SessionID:     c.BuildOpts().SessionID,
+	// BuildKit passes SOURCE_DATE_EPOCH as a build arg instead of setting it in the environment of the frontend
+	created, err := getCreatedTime(time.Time{}, buildArgs["SOURCE_DATE_EPOCH"])
+	if err != nil {
+		return nil, err
+	}
+
+	return SolvePlan(ctx, c, plan, buildPlatforms, ConvertPlanOptions{
+		SecretsHash: secretsHash,
+		CacheKey:    cacheKey,
+		SessionID:   c.BuildOpts().SessionID,
+		Created:     created,
 	})
+}
+
+// SolvePlan converts the plan once per platform and solves it with the gateway client
+// Multiple platforms return a ref per platform so that the result is exported as a manifest list
+func SolvePlan(ctx context.Context, c client.Client, plan *plan.BuildPlan, buildPlatforms []BuildPlatform, opts ConvertPlanOptions) (*client.Result, error) {
+	if len(buildPlatforms) == 0 {
+		buildPlatforms = []BuildPlatform{DetermineBuildPlatformFromHost()}
+	}
+
+	// Use the same creation time for every platform so the labels match across the manifest list
+	created, err := getCreatedTime(opts.Created, os.Getenv("SOURCE_DATE_EPOCH"))
+	if err != nil {
+		return nil, err
+	}
+	opts.Created = created
+
+	res := client.NewResult()
+	labels := getImageLabels(plan, opts.Created)
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		res.AddMeta(exptypes.AnnotationManifestKey(nil, key), []byte(labels[key]))
+		if len(buildPlatforms) > 1 {
+			res.AddMeta(exptypes.AnnotationIndexKey(key), []byte(labels[key]))
+		}
+	}
+
+	if len(buildPlatforms) == 1 {
+		ref, imageBytes, err := solvePlatform(ctx, c, plan, buildPlatforms[0], opts)
+		if err != nil {
+			return nil, err
+		}
+
+		res.SetRef(ref)
+		res.AddMeta(exptypes.ExporterImageConfigKey, imageBytes)
+		return res, nil
+	}
+
+	expPlatforms := &exptypes.Platforms{
+		Platforms: make([]exptypes.Platform, 0, len(buildPlatforms)),
+	}
+
+	for _, buildPlatform := range buildPlatforms {
+		ref, imageBytes, err := solvePlatform(ctx, c, plan, buildPlatform, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		id := buildPlatform.ID()
+		res.AddRef(id, ref)
+		res.AddMeta(fmt.Sprintf("%s/%s", exptypes.ExporterImageConfigKey, id), imageBytes)
+		expPlatforms.Platforms = append(expPlatforms.Platforms, exptypes.Platform{
+			ID:       id,
+			Platform: buildPlatform.ToPlatform(),
+		})
+	}
+
+	platformBytes, err := json.Marshal(expPlatforms)
 	if err != nil {
-		return nil, fmt.Errorf("error converting plan to LLB: %w", err)
+		return nil, fmt.Errorf("error marshalling platforms: %w", err)
 	}
+	res.AddMeta(exptypes.ExporterPlatformsKey, platformBytes)
 
-	def, err := llbState.Marshal(ctx)
+	return res, nil
+}
+
+// solvePlatform converts and solves the plan for a single platform
+// Returns the solved reference and the serialized image config
+func solvePlatform(ctx context.Context, c client.Client, plan *plan.BuildPlan, buildPlatform BuildPlatform, opts ConvertPlanOptions) (client.Reference, []byte, error) {
+	opts.BuildPlatform = buildPlatform
+
+	llbState, image, err := ConvertPlanToLLB(plan, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error converting plan to LLB: %w", err)
+	}
+
+	def, err := llbState.Marshal(ctx, llb.Platform(buildPlatform.ToPlatform()))
 	if err != nil {
-		return nil, fmt.Errorf("error marshalling LLB state: %w", err)
+		return nil, nil, fmt.Errorf("error marshalling LLB state: %w", err)
 	}
 
 	imageBytes, err := json.Marshal(image)
 	if err != nil {
-		return nil, fmt.Errorf("error marshalling image: %w", err)
+		return nil, nil, fmt.Errorf("error marshalling image: %w", err)
 	}
 
 	res, err := c.Solve(ctx, client.SolveRequest{
 		Definition: def.ToPB(),
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	res.AddMeta(exptypes.ExporterImageConfigKey, imageBytes)
+	ref, err := res.SingleRef()
+	if err != nil {
+		return nil, nil, err
+	}
 
-	return res, nil
+	return ref, imageBytes, nil
 }
 
 func readRailpackPlan(ctx context.Context, c client.Client) (*plan.BuildPlan, error) {
@@ -107,8 +189,18 @@ func readRailpackPlan(ctx context.Context, c client.Client) (*plan.BuildPlan, er
 		return nil, errors.Wrap(err, "failed to read railpack plan")
 	}
 
+	// Upgrade plans written by older versions of Railpack before checking them against the current schema
+	migratedContents, err := plan.MigratePlan([]byte(fileContents))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to migrate railpack plan")
+	}
+
+	if err := validateRailpackPlan(migratedContents); err != nil {
+		return nil, err
+	}
+
 	plan := plan.NewBuildPlan()
-	err = json.Unmarshal([]byte(fileContents), plan)
+	err = json.Unmarshal(migratedContents, plan)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse railpack plan")
 	}
@@ -116,31 +208,35 @@ func readRailpackPlan(ctx context.Context, c client.Client) (*plan.BuildPlan, er
 	return plan, nil
 }
 
-// validatePlatform checks if the platform is supported and returns the corresponding BuildPlatform
-func validatePlatform(opts map[string]string) (BuildPlatform, error) {
-	platformStr := opts["platform"]
-	if platformStr == "" {
-		// Default to host platform if none specified
-		return DetermineBuildPlatformFromHost(), nil
+// validateRailpackPlan checks the serialized plan against the plan schema,
+// so plans written by other tools fail with a clear error instead of being misread
+func validateRailpackPlan(contents []byte) error {
+	var value any
+	if err := json.Unmarshal(contents, &value); err != nil {
+		return errors.Wrap(err, "failed to parse railpack plan")
 	}
 
-	// Error if multiple platforms are specified
-	if strings.Contains(platformStr, ",") {
-		return BuildPlatform{}, fmt.Errorf("multiple platforms are not supported, got: %s", platformStr)
+	schemaErrors, err := utils.ValidateJSONSchema(plan.GetJsonSchema(), value)
+	if err != nil {
+		return errors.Wrap(err, "failed to validate railpack plan")
+	}
+
+	if len(schemaErrors) == 0 {
+		return nil
 	}
 
-	// Match against supported platforms
-	switch platformStr {
-	case PlatformLinuxAMD64.String():
-		return PlatformLinuxAMD64, nil
-	case PlatformLinuxARM64.String():
-		return PlatformLinuxARM64, nil
-	default:
-		return BuildPlatform{}, fmt.Errorf("unsupported platform: %s. Must be one of: %s, %s",
-			platformStr,
-			PlatformLinuxAMD64.String(),
-			PlatformLinuxARM64.String())
+	messages := make([]string, 0, len(schemaErrors))
+	for _, schemaError := range schemaErrors {
+		messages = append(messages, schemaError.Error())
 	}
+
+	return fmt.Errorf("railpack plan does not match the plan schema:\n  %s", strings.Join(messages, "\n  "))
+}
+
+// validatePlatforms checks if the requested platforms are supported and returns the corresponding BuildPlatforms
+func validatePlatforms(opts map[string]string) ([]BuildPlatform, error) {
+	// Default to host platform if none specified
+	return ParsePlatforms(opts["platform"])
 }
 
 // Read a file from the build context
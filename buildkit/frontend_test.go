@@ -1,7 +1,11 @@
 package buildkit
 
 import (
+	"encoding/json"
 	"testing"
+
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseBuildArgs(t *testing.T) {
@@ -29,3 +33,33 @@ func TestParseBuildArgs(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateRailpackPlan(t *testing.T) {
+	buildPlan := plan.NewBuildPlan()
+	step := plan.NewStep("build")
+	step.Inputs = []plan.Input{plan.NewImageInput(plan.RAILPACK_BUILDER_IMAGE), plan.NewLocalInput(".")}
+	step.Commands = []plan.Command{
+		plan.NewExecShellCommand("npm run build"),
+		plan.NewPathCommand("/app/node_modules/.bin"),
+		plan.NewCopyCommand("package.json"),
+		plan.NewFileCommand("/app/.npmrc", "npmrc"),
+	}
+	step.Assets["npmrc"] = "registry=https://registry.npmjs.org/"
+	buildPlan.AddStep(*step)
+	buildPlan.Caches["npm"] = plan.NewCache("/root/.npm")
+	buildPlan.Deploy = plan.Deploy{
+		Inputs:      []plan.Input{plan.NewStepInput("build")},
+		StartCmd:    "npm start",
+		Healthcheck: &plan.Healthcheck{Cmd: "curl -f http://localhost/"},
+	}
+
+	contents, err := json.Marshal(buildPlan)
+	require.NoError(t, err)
+	require.NoError(t, validateRailpackPlan(contents))
+
+	err = validateRailpackPlan([]byte(`{"steps": [{"commands": [{"cmd": 1}]}], "deploy": {"startCmd": "npm start"}}`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `/steps/0: missing required property "name"`)
+	require.Contains(t, err.Error(), `/steps/0/commands/0/cmd: expected string but got integer`)
+	require.Contains(t, err.Error(), `/deploy/startCmd: unknown property "startCmd"`)
+}
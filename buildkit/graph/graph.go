@@ -36,6 +36,12 @@ func (g *Graph) GetNode(name string) (Node, bool) {
 	return node, exists
 }
 
+// AddEdge adds a directed edge from parent to child
+func (g *Graph) AddEdge(parent, child Node) {
+	child.SetParents(append(child.GetParents(), parent))
+	parent.SetChildren(append(parent.GetChildren(), child))
+}
+
 // GetNodes returns all nodes in the graph
 func (g *Graph) GetNodes() map[string]Node {
 	return g.nodes
@@ -175,3 +175,46 @@ func TestTransitiveDependencies(t *testing.T) {
 		}
 	}
 }
+
+func TestGraphRender(t *testing.T) {
+	g := NewGraph()
+
+	nodeA := NewTestNode("A")
+	nodeB := NewTestNode("B \"quoted\"")
+	nodeC := NewTestNode("C")
+
+	g.AddNode(nodeC)
+	g.AddNode(nodeB)
+	g.AddNode(nodeA)
+
+	g.AddEdge(nodeA, nodeB)
+	g.AddEdge(nodeB, nodeC)
+
+	expectedDot := `digraph railpack {
+  rankdir=LR;
+  node [fontname="Helvetica"];
+  edge [fontname="Helvetica", fontsize=10];
+
+  "A" [label="A", shape=box];
+  "B \"quoted\"" [label="B \"quoted\"", shape=box];
+  "C" [label="C", shape=box];
+
+  "A" -> "B \"quoted\"";
+  "B \"quoted\"" -> "C";
+}
+`
+	if dot := g.RenderDot(); dot != expectedDot {
+		t.Errorf("Unexpected dot output:\n%s", dot)
+	}
+
+	expectedMermaid := `flowchart LR
+  n0["A"]
+  n1["B #quot;quoted#quot;"]
+  n2["C"]
+  n0 --> n1
+  n1 --> n2
+`
+	if mermaid := g.RenderMermaid(); mermaid != expectedMermaid {
+		t.Errorf("Unexpected mermaid output:\n%s", mermaid)
+	}
+}
@@ -0,0 +1,190 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// NodeKind describes what a node represents when the graph is rendered
+type NodeKind int
+
+const (
+	NodeKindLocal NodeKind = iota
+	NodeKindImage
+	NodeKindStep
+	NodeKindDeploy
+)
+
+// RenderableNode is a node that describes how it is rendered.
+// Nodes that don't implement it are rendered as steps labelled with their name
+type RenderableNode interface {
+	Node
+	GetLabel() string
+	GetKind() NodeKind
+	GetEdgeLabel(parent Node) string
+}
+
+// RenderDot renders the graph in the Graphviz DOT format
+func (g *Graph) RenderDot() string {
+	var sb strings.Builder
+	sb.WriteString("digraph railpack {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [fontname=\"Helvetica\"];\n")
+	sb.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	nodes := g.sortedNodes()
+
+	if len(nodes) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, node := range nodes {
+		label, kind := nodeDisplay(node)
+		sb.WriteString(fmt.Sprintf("  %s [label=%s, %s];\n", dotQuote(node.GetName()), dotQuote(label), dotShapes[kind]))
+	}
+
+	edges := g.edges(nodes)
+
+	if len(edges) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, edge := range edges {
+		sb.WriteString(fmt.Sprintf("  %s -> %s", dotQuote(edge.parent.GetName()), dotQuote(edge.child.GetName())))
+		if edge.label != "" {
+			sb.WriteString(fmt.Sprintf(" [label=%s]", dotQuote(edge.label)))
+		}
+		sb.WriteString(";\n")
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// RenderMermaid renders the graph as a Mermaid flowchart
+func (g *Graph) RenderMermaid() string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+
+	nodes := g.sortedNodes()
+
+	// Node names can contain characters Mermaid doesn't allow in ids, so every node gets a generated id
+	ids := make(map[string]string, len(nodes))
+	for i, node := range nodes {
+		ids[node.GetName()] = fmt.Sprintf("n%d", i)
+
+		label, kind := nodeDisplay(node)
+		shape := mermaidShapes[kind]
+		sb.WriteString(fmt.Sprintf("  %s%s%s%s\n", ids[node.GetName()], shape[0], mermaidQuote(label), shape[1]))
+	}
+
+	for _, edge := range g.edges(nodes) {
+		sb.WriteString(fmt.Sprintf("  %s -->", ids[edge.parent.GetName()]))
+		if edge.label != "" {
+			sb.WriteString(fmt.Sprintf("|%s|", mermaidQuote(edge.label)))
+		}
+		sb.WriteString(fmt.Sprintf(" %s\n", ids[edge.child.GetName()]))
+	}
+
+	return sb.String()
+}
+
+var dotShapes = map[NodeKind]string{
+	NodeKindStep:   "shape=box",
+	NodeKindLocal:  "shape=folder",
+	NodeKindImage:  "shape=box, style=rounded",
+	NodeKindDeploy: "shape=doubleoctagon",
+}
+
+var mermaidShapes = map[NodeKind][2]string{
+	NodeKindStep:   {"[", "]"},
+	NodeKindLocal:  {"[(", ")]"},
+	NodeKindImage:  {"([", "])"},
+	NodeKindDeploy: {"{{", "}}"},
+}
+
+type edge struct {
+	parent Node
+	child  Node
+	label  string
+}
+
+// sortedNodes returns the nodes grouped by kind, then ordered by their depth in the graph and their name
+// so the output is stable
+func (g *Graph) sortedNodes() []Node {
+	nodes := make([]Node, 0, len(g.nodes))
+	for _, node := range g.nodes {
+		nodes = append(nodes, node)
+	}
+
+	depths := make(map[string]int, len(nodes))
+	var depth func(node Node, visiting map[string]bool) int
+	depth = func(node Node, visiting map[string]bool) int {
+		if d, ok := depths[node.GetName()]; ok {
+			return d
+		}
+
+		// Guard against cycles so an invalid plan can still be rendered
+		if visiting[node.GetName()] {
+			return 0
+		}
+		visiting[node.GetName()] = true
+
+		d := 0
+		for _, parent := range node.GetParents() {
+			d = max(d, depth(parent, visiting)+1)
+		}
+
+		depths[node.GetName()] = d
+		return d
+	}
+
+	for _, node := range nodes {
+		depth(node, map[string]bool{})
+	}
+
+	slices.SortFunc(nodes, func(a, b Node) int {
+		_, kindA := nodeDisplay(a)
+		_, kindB := nodeDisplay(b)
+		if kindA != kindB {
+			return int(kindA) - int(kindB)
+		}
+		if depths[a.GetName()] != depths[b.GetName()] {
+			return depths[a.GetName()] - depths[b.GetName()]
+		}
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+
+	return nodes
+}
+
+func (g *Graph) edges(nodes []Node) []edge {
+	edges := []edge{}
+	for _, child := range nodes {
+		for _, parent := range child.GetParents() {
+			label := ""
+			if renderable, ok := child.(RenderableNode); ok {
+				label = renderable.GetEdgeLabel(parent)
+			}
+			edges = append(edges, edge{parent: parent, child: child, label: label})
+		}
+	}
+	return edges
+}
+
+func nodeDisplay(node Node) (string, NodeKind) {
+	if renderable, ok := node.(RenderableNode); ok {
+		return renderable.GetLabel(), renderable.GetKind()
+	}
+	return node.GetName(), NodeKindStep
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+func mermaidQuote(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	return `"` + strings.ReplaceAll(s, "\n", "<br>") + `"`
+}
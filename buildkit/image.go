@@ -1,6 +1,9 @@
 package buildkit
 
-import specs "github.com/opencontainers/image-spec/specs-go/v1"
+import (
+	dockerspec "github.com/moby/docker-image-spec/specs-go/v1"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
 
 // Image is the JSON structure which describes some basic information about the image.
 // This provides the `application/vnd.oci.image.config.v1+json` mediatype when marshalled to JSON.
@@ -8,7 +11,8 @@ type Image struct {
 	specs.Image
 
 	// Config defines the execution parameters which should be used as a base when running a container using the image.
-	Config specs.ImageConfig `json:"config,omitempty"`
+	// The Docker extensions to the config carry the healthcheck.
+	Config dockerspec.DockerOCIImageConfig `json:"config,omitempty"`
 
 	// Variant defines platform variant. To be added to OCI.
 	Variant string `json:"variant,omitempty"`
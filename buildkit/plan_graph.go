@@ -0,0 +1,132 @@
+package buildkit
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/railpack/buildkit/graph"
+	p "github.com/railwayapp/railpack/core/plan"
+)
+
+const (
+	PlanGraphFormatDot     = "dot"
+	PlanGraphFormatMermaid = "mermaid"
+)
+
+// planGraphNode is a step, input, or the deploy section of a plan
+type planGraphNode struct {
+	name       string
+	label      string
+	kind       graph.NodeKind
+	parents    []graph.Node
+	children   []graph.Node
+	edgeLabels map[string][]string
+}
+
+func (n *planGraphNode) GetName() string            { return n.name }
+func (n *planGraphNode) GetParents() []graph.Node   { return n.parents }
+func (n *planGraphNode) GetChildren() []graph.Node  { return n.children }
+func (n *planGraphNode) SetParents(p []graph.Node)  { n.parents = p }
+func (n *planGraphNode) SetChildren(c []graph.Node) { n.children = c }
+func (n *planGraphNode) GetLabel() string           { return n.label }
+func (n *planGraphNode) GetKind() graph.NodeKind    { return n.kind }
+func (n *planGraphNode) GetEdgeLabel(parent graph.Node) string {
+	return strings.Join(n.edgeLabels[parent.GetName()], "\n")
+}
+
+type planGraphBuilder struct {
+	graph *graph.Graph
+}
+
+// ConvertPlanToGraph creates a graph of the steps of a plan, the inputs they use, and the deploy section
+func ConvertPlanToGraph(plan *p.BuildPlan) *graph.Graph {
+	b := &planGraphBuilder{graph: graph.NewGraph()}
+
+	for _, step := range plan.Steps {
+		b.getNode("step:"+step.Name, step.Name, graph.NodeKindStep)
+	}
+
+	for _, step := range plan.Steps {
+		b.addInputs(b.getNode("step:"+step.Name, step.Name, graph.NodeKindStep), step.Inputs)
+	}
+
+	deploy := b.getNode("deploy", "deploy", graph.NodeKindDeploy)
+	if plan.Deploy.StartCmd != "" {
+		deploy.label = fmt.Sprintf("deploy\n$ %s", plan.Deploy.StartCmd)
+	}
+	b.addInputs(deploy, plan.Deploy.Inputs)
+
+	return b.graph
+}
+
+// RenderPlanGraph renders the graph of a plan in the given format (dot or mermaid)
+func RenderPlanGraph(plan *p.BuildPlan, format string) (string, error) {
+	g := ConvertPlanToGraph(plan)
+
+	switch format {
+	case PlanGraphFormatDot:
+		return g.RenderDot(), nil
+	case PlanGraphFormatMermaid:
+		return g.RenderMermaid(), nil
+	default:
+		return "", fmt.Errorf("unknown graph format %q", format)
+	}
+}
+
+func (b *planGraphBuilder) getNode(name, label string, kind graph.NodeKind) *planGraphNode {
+	if node, exists := b.graph.GetNode(name); exists {
+		return node.(*planGraphNode)
+	}
+
+	node := &planGraphNode{
+		name:       name,
+		label:      label,
+		kind:       kind,
+		parents:    []graph.Node{},
+		children:   []graph.Node{},
+		edgeLabels: map[string][]string{},
+	}
+	b.graph.AddNode(node)
+
+	return node
+}
+
+func (b *planGraphBuilder) addInputs(node *planGraphNode, inputs []p.Input) {
+	for _, input := range inputs {
+		var parent *planGraphNode
+		switch {
+		case input.Step != "":
+			parent = b.getNode("step:"+input.Step, input.Step, graph.NodeKindStep)
+		case input.Image != "":
+			parent = b.getNode("image:"+input.Image, input.Image, graph.NodeKindImage)
+		case input.Local:
+			parent = b.getNode("local", "local", graph.NodeKindLocal)
+		default:
+			continue
+		}
+
+		// The same parent can be used several times with different filters, which are shown on a single edge
+		if !slices.Contains(node.parents, graph.Node(parent)) {
+			b.graph.AddEdge(parent, node)
+		}
+
+		if label := inputEdgeLabel(input); label != "" {
+			node.edgeLabels[parent.name] = append(node.edgeLabels[parent.name], label)
+		}
+	}
+}
+
+func inputEdgeLabel(input p.Input) string {
+	parts := []string{}
+	if input.Spread {
+		parts = append(parts, "spread")
+	}
+	if len(input.Include) > 0 {
+		parts = append(parts, "include: "+strings.Join(input.Include, ", "))
+	}
+	if len(input.Exclude) > 0 {
+		parts = append(parts, "exclude: "+strings.Join(input.Exclude, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
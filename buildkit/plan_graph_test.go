@@ -0,0 +1,56 @@
+package buildkit
+
+import (
+	"testing"
+
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPlanGraph(t *testing.T) {
+	buildPlan := plan.NewBuildPlan()
+
+	miseStep := plan.NewStep("packages:mise")
+	miseStep.Inputs = []plan.Input{plan.NewImageInput("builder")}
+
+	buildStep := plan.NewStep("build")
+	buildStep.Inputs = []plan.Input{
+		plan.NewStepInput("packages:mise"),
+		plan.NewLocalInput("."),
+	}
+
+	buildPlan.AddStep(*buildStep)
+	buildPlan.AddStep(*miseStep)
+
+	buildPlan.Deploy = plan.Deploy{
+		StartCmd: "npm start",
+		Inputs: []plan.Input{
+			plan.NewImageInput("runtime"),
+			plan.NewStepInput("packages:mise", plan.InputOptions{Include: []string{"/mise/shims"}}),
+			plan.NewStepInput("build", plan.InputOptions{Include: []string{"."}, Exclude: []string{"node_modules"}}),
+			plan.NewStepInput("build", plan.InputOptions{Include: []string{"/app/node_modules"}}),
+		},
+	}
+
+	mermaid, err := RenderPlanGraph(buildPlan, PlanGraphFormatMermaid)
+	require.NoError(t, err)
+
+	expected := `flowchart LR
+  n0[("local")]
+  n1(["builder"])
+  n2(["runtime"])
+  n3["packages:mise"]
+  n4["build"]
+  n5{{"deploy<br>$ npm start"}}
+  n1 --> n3
+  n3 --> n4
+  n0 -->|"include: ."| n4
+  n2 --> n5
+  n3 -->|"include: /mise/shims"| n5
+  n4 -->|"include: .; exclude: node_modules<br>include: /app/node_modules"| n5
+`
+	require.Equal(t, expected, mermaid)
+
+	_, err = RenderPlanGraph(buildPlan, "svg")
+	require.Error(t, err)
+}
@@ -3,7 +3,10 @@ package buildkit
 import (
 	"fmt"
 	"runtime"
+	"slices"
+	"strings"
 
+	"github.com/containerd/platforms"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -32,10 +35,52 @@ func DetermineBuildPlatformFromHost() BuildPlatform {
 	return PlatformLinuxAMD64
 }
 
+// ParsePlatform returns the supported BuildPlatform for a platform string (e.g. linux/amd64)
+func ParsePlatform(platformStr string) (BuildPlatform, error) {
+	switch strings.TrimSpace(platformStr) {
+	case PlatformLinuxAMD64.String():
+		return PlatformLinuxAMD64, nil
+	case PlatformLinuxARM64.String():
+		return PlatformLinuxARM64, nil
+	default:
+		return BuildPlatform{}, fmt.Errorf("unsupported platform: %s. Must be one of: %s, %s",
+			platformStr,
+			PlatformLinuxAMD64.String(),
+			PlatformLinuxARM64.String())
+	}
+}
+
+// ParsePlatforms parses a comma separated list of platforms (e.g. linux/amd64,linux/arm64)
+// The host platform is used if the list is empty
+func ParsePlatforms(platformsStr string) ([]BuildPlatform, error) {
+	if strings.TrimSpace(platformsStr) == "" {
+		return []BuildPlatform{DetermineBuildPlatformFromHost()}, nil
+	}
+
+	buildPlatforms := []BuildPlatform{}
+	for _, platformStr := range strings.Split(platformsStr, ",") {
+		platform, err := ParsePlatform(platformStr)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(buildPlatforms, platform) {
+			buildPlatforms = append(buildPlatforms, platform)
+		}
+	}
+
+	return buildPlatforms, nil
+}
+
 func (p BuildPlatform) String() string {
 	return fmt.Sprintf("%s/%s", p.OS, p.Architecture)
 }
 
+// ID returns the normalized platform identifier used to key multi-platform results
+func (p BuildPlatform) ID() string {
+	return platforms.Format(platforms.Normalize(p.ToPlatform()))
+}
+
 func (p BuildPlatform) ToPlatform() specs.Platform {
 	return specs.Platform{
 		OS:           p.OS,
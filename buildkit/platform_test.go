@@ -0,0 +1,31 @@
+package buildkit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlatforms(t *testing.T) {
+	t.Run("empty defaults to host", func(t *testing.T) {
+		platforms, err := ParsePlatforms("")
+		require.NoError(t, err)
+		require.Equal(t, []BuildPlatform{DetermineBuildPlatformFromHost()}, platforms)
+	})
+
+	t.Run("multiple platforms", func(t *testing.T) {
+		platforms, err := ParsePlatforms("linux/amd64, linux/arm64,linux/amd64")
+		require.NoError(t, err)
+		require.Equal(t, []BuildPlatform{PlatformLinuxAMD64, PlatformLinuxARM64}, platforms)
+	})
+
+	t.Run("unsupported platform", func(t *testing.T) {
+		_, err := ParsePlatforms("linux/amd64,windows/amd64")
+		require.Error(t, err)
+	})
+}
+
+func TestBuildPlatformID(t *testing.T) {
+	require.Equal(t, "linux/amd64", PlatformLinuxAMD64.ID())
+	require.Equal(t, "linux/arm64", PlatformLinuxARM64.ID())
+}
@@ -23,15 +23,29 @@ var BuildCommand = &cli.Command{
 	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:  "name",
-			Usage: "name of the image to build",
+			Usage: "name of the image to build (e.g. registry.example.com/repo:tag when pushing)",
+		},
+		&cli.BoolFlag{
+			Name:  "push",
+			Usage: "push the image to the registry in --name instead of loading it into Docker",
+			Value: false,
 		},
 		&cli.StringFlag{
 			Name:  "output",
 			Usage: "output the final filesystem to a local directory",
 		},
+		&cli.StringFlag{
+			Name:  "output-tar",
+			Usage: "write the image as a tarball to a file instead of loading it into Docker",
+		},
+		&cli.StringFlag{
+			Name:  "output-format",
+			Usage: "format of the image tarball written with --output-tar. Values: oci, docker",
+			Value: "oci",
+		},
 		&cli.StringFlag{
 			Name:  "platform",
-			Usage: "platform to build for (e.g. linux/amd64, linux/arm64)",
+			Usage: "comma separated platforms to build for (e.g. linux/amd64, linux/arm64, linux/amd64,linux/arm64)",
 		},
 		&cli.StringFlag{
 			Name:  "progress",
@@ -47,6 +61,14 @@ var BuildCommand = &cli.Command{
 			Name:  "cache-key",
 			Usage: "Unique id to prefix to cache keys",
 		},
+		&cli.StringSliceFlag{
+			Name:  "cache-from",
+			Usage: "external cache sources (e.g. type=local,src=path/to/dir or type=registry,ref=example.com/app:cache)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "cache-to",
+			Usage: "cache export destinations (e.g. type=local,dest=path/to/dir or type=registry,ref=example.com/app:cache,mode=max)",
+		},
 	}, commonPlanFlags()...),
 	Action: func(ctx context.Context, cmd *cli.Command) error {
 		buildResult, app, env, err := GenerateBuildResultForCommand(cmd)
@@ -77,7 +99,7 @@ var BuildCommand = &cli.Command{
 
 		secretsHash := getSecretsHash(env)
 
-		platform, err := getPlatform(cmd.String("platform"))
+		platforms, err := buildkit.ParsePlatforms(cmd.String("platform"))
 		if err != nil {
 			return cli.Exit(err, 1)
 		}
@@ -86,11 +108,16 @@ var BuildCommand = &cli.Command{
 			ImageName:    cmd.String("name"),
 			DumpLLB:      cmd.Bool("llb"),
 			OutputDir:    cmd.String("output"),
+			OutputTar:    cmd.String("output-tar"),
+			OutputFormat: cmd.String("output-format"),
+			Push:         cmd.Bool("push"),
 			ProgressMode: cmd.String("progress"),
 			CacheKey:     cmd.String("cache-key"),
+			ImportCache:  cmd.StringSlice("cache-from"),
+			ExportCache:  cmd.StringSlice("cache-to"),
 			SecretsHash:  secretsHash,
 			Secrets:      env.Variables,
-			Platform:     platform,
+			Platforms:    platforms,
 		})
 		if err != nil {
 			return cli.Exit(err, 1)
@@ -100,21 +127,6 @@ var BuildCommand = &cli.Command{
 	},
 }
 
-func getPlatform(platformStr string) (buildkit.BuildPlatform, error) {
-	var platform buildkit.BuildPlatform
-	if platformStr == "" {
-		platform = buildkit.DetermineBuildPlatformFromHost()
-	} else if platformStr == "linux/arm64" {
-		platform = buildkit.PlatformLinuxARM64
-	} else if platformStr != "linux/amd64" {
-		return buildkit.BuildPlatform{}, fmt.Errorf("unsupported platform: %s. Must be one of: linux/amd64, linux/arm64", platformStr)
-	} else {
-		platform = buildkit.PlatformLinuxAMD64
-	}
-
-	return platform, nil
-}
-
 func validateSecrets(plan *plan.BuildPlan, env *app.Environment) error {
 	for _, secret := range plan.Secrets {
 		if _, ok := env.Variables[secret]; !ok {
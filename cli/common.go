@@ -34,6 +34,10 @@ func commonPlanFlags() []cli.Flag {
 			Name:  "config-file",
 			Usage: "path to config file to use",
 		},
+		&cli.StringFlag{
+			Name:  "environment",
+			Usage: "name of the config environment to apply (e.g. staging or production)",
+		},
 		&cli.BoolFlag{
 			Name:  "error-missing-start",
 			Usage: "error if no start command is found",
@@ -48,6 +52,11 @@ func GenerateBuildResultForCommand(cmd *cli.Command) (*core.BuildResult, *a.App,
 		return nil, nil, nil, cli.Exit("directory argument is required", 1)
 	}
 
+	return generateBuildResultForDirectory(cmd, directory)
+}
+
+// generateBuildResultForDirectory generates a build result for the directory using the plan flags of the command
+func generateBuildResultForDirectory(cmd *cli.Command, directory string) (*core.BuildResult, *a.App, *a.Environment, error) {
 	app, err := a.NewApp(directory)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("error creating app: %w", err)
@@ -70,6 +79,7 @@ func GenerateBuildResultForCommand(cmd *cli.Command) (*core.BuildResult, *a.App,
 		StartCommand:             cmd.String("start-cmd"),
 		PreviousVersions:         previousVersions,
 		ConfigFilePath:           cmd.String("config-file"),
+		Environment:              cmd.String("environment"),
 		ErrorMissingStartCommand: cmd.Bool("error-missing-start"),
 	}
 
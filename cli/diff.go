@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/railwayapp/railpack/core"
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/urfave/cli/v3"
+)
+
+var DiffCommand = &cli.Command{
+	Name:                  "diff",
+	Usage:                 "show the differences between the build plans of two apps or plan files",
+	ArgsUsage:             "A B",
+	Description:           "Each side can be an app directory, a build plan JSON file, or a build result JSON file (e.g. from `prepare --info-out`)",
+	EnableShellCompletion: true,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "output format. one of: pretty, json",
+			Value: "pretty",
+		},
+		&cli.BoolFlag{
+			Name:  "exit-code",
+			Usage: "exit with code 1 if there are differences",
+		},
+	}, commonPlanFlags()...),
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		if cmd.Args().Len() != 2 {
+			return cli.Exit("exactly two arguments are required", 1)
+		}
+
+		before, err := loadBuildResultForDiff(cmd, cmd.Args().Get(0))
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		after, err := loadBuildResultForDiff(cmd, cmd.Args().Get(1))
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		changes := core.DiffBuildResults(before, after)
+
+		if cmd.String("format") == "json" {
+			if changes == nil {
+				changes = []core.Change{}
+			}
+
+			serializedChanges, err := json.MarshalIndent(changes, "", "  ")
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+
+			os.Stdout.Write(serializedChanges)
+			os.Stdout.Write([]byte("\n"))
+		} else {
+			os.Stdout.Write([]byte(core.FormatChanges(changes)))
+		}
+
+		if cmd.Bool("exit-code") && len(changes) > 0 {
+			return cli.Exit("", 1)
+		}
+
+		return nil
+	},
+}
+
+// loadBuildResultForDiff generates a build result for an app directory or reads it from a plan or build result file
+func loadBuildResultForDiff(cmd *cli.Command, path string) (*core.BuildResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	if info.IsDir() {
+		buildResult, _, _, err := generateBuildResultForDirectory(cmd, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !buildResult.Success {
+			return nil, fmt.Errorf("failed to generate build plan for %s", path)
+		}
+
+		return buildResult, nil
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(contents, &fields); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+
+	// Build plans have steps and a deploy section at the top level, while build results nest them under the plan
+	_, hasSteps := fields["steps"]
+	_, hasDeploy := fields["deploy"]
+	if hasSteps || hasDeploy {
+		// Compare plans from older versions of Railpack in the current format
+		contents, err = plan.MigratePlan(contents)
+		if err != nil {
+			return nil, fmt.Errorf("error migrating build plan %s: %w", path, err)
+		}
+
+		buildPlan := &plan.BuildPlan{}
+		if err := json.Unmarshal(contents, buildPlan); err != nil {
+			return nil, fmt.Errorf("error parsing build plan %s: %w", path, err)
+		}
+
+		return &core.BuildResult{Plan: buildPlan}, nil
+	}
+
+	rawPlan, hasPlan := fields["plan"]
+	if !hasPlan || string(rawPlan) == "null" {
+		return nil, fmt.Errorf("build result %s does not include a build plan. Use a result from `prepare --info-out` of a newer version of Railpack, or the plan file from `prepare --plan-out`", path)
+	}
+
+	// Compare plans from older versions of Railpack in the current format
+	migratedPlan, err := plan.MigratePlan(rawPlan)
+	if err != nil {
+		return nil, fmt.Errorf("error migrating the build plan of %s: %w", path, err)
+	}
+	fields["plan"] = migratedPlan
+
+	contents, err = json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing build result %s: %w", path, err)
+	}
+
+	buildResult := &core.BuildResult{}
+	if err := json.Unmarshal(contents, buildResult); err != nil {
+		return nil, fmt.Errorf("error parsing build result %s: %w", path, err)
+	}
+
+	return buildResult, nil
+}
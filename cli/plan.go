@@ -3,10 +3,13 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/charmbracelet/log"
+	"github.com/railwayapp/railpack/buildkit"
+	"github.com/railwayapp/railpack/core"
 	"github.com/urfave/cli/v3"
 )
 
@@ -22,6 +25,11 @@ var PlanCommand = &cli.Command{
 			Aliases: []string{"o"},
 			Usage:   "output file name",
 		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "output format. one of: json, dot, mermaid",
+			Value: "json",
+		},
 	}, commonPlanFlags()...),
 	Action: func(ctx context.Context, cmd *cli.Command) error {
 		buildResult, _, _, err := GenerateBuildResultForCommand(cmd)
@@ -29,11 +37,28 @@ var PlanCommand = &cli.Command{
 			return cli.Exit(err, 1)
 		}
 
-		serializedPlan, err := json.MarshalIndent(buildResult.Plan, "", "  ")
-		if err != nil {
-			return cli.Exit(err, 1)
+		if !buildResult.Success {
+			core.PrettyPrintBuildResult(buildResult, core.PrintOptions{Version: Version})
+			os.Exit(1)
+			return nil
+		}
+
+		var buildResultString string
+		switch format := cmd.String("format"); format {
+		case "json":
+			serializedPlan, err := json.MarshalIndent(buildResult.Plan, "", "  ")
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+			buildResultString = string(serializedPlan)
+		case buildkit.PlanGraphFormatDot, buildkit.PlanGraphFormatMermaid:
+			buildResultString, err = buildkit.RenderPlanGraph(buildResult.Plan, format)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+		default:
+			return cli.Exit(fmt.Sprintf("unknown format %q", format), 1)
 		}
-		buildResultString := string(serializedPlan)
 
 		output := cmd.String("out")
 		if output == "" {
@@ -50,7 +50,6 @@ var PrepareCommand = &cli.Command{
 
 		// Save info if requested
 		if infoOut := cmd.String("info-out"); infoOut != "" {
-			buildResult.Plan = nil
 			if err := writeJSONFile(infoOut, buildResult, "Build result info written to %s"); err != nil {
 				return cli.Exit(err, 1)
 			}
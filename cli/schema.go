@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/railwayapp/railpack/core/config"
+	"github.com/railwayapp/railpack/core/plan"
 	"github.com/urfave/cli/v3"
 )
 
@@ -13,9 +14,17 @@ var SchemaCommand = &cli.Command{
 	Name:                  "schema",
 	Usage:                 "outputs the JSON schema for the Railpack config",
 	EnableShellCompletion: true,
-	Flags:                 []cli.Flag{},
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "plan",
+			Usage: "output the schema for the serialized build plan instead of the config",
+		},
+	},
 	Action: func(ctx context.Context, cmd *cli.Command) error {
 		schema := config.GetJsonSchema()
+		if cmd.Bool("plan") {
+			schema = plan.GetJsonSchema()
+		}
 
 		schemaJson, err := json.MarshalIndent(schema, "", "  ")
 		if err != nil {
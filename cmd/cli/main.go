@@ -35,6 +35,8 @@ func main() {
 		cli.PrepareCommand,
 		cli.InfoCommand,
 		cli.PlanCommand,
+		cli.DockerfileCommand,
+		cli.DiffCommand,
 		cli.SchemaCommand,
 		cli.FrontendCommand,
 	}
@@ -14,18 +14,41 @@ type DeployConfig struct {
 	AptPackages []string          `json:"aptPackages,omitempty" jsonschema:"description=List of apt packages to include at runtime"`
 	Inputs      []plan.Input      `json:"inputs,omitempty" jsonschema:"description=The inputs for the deploy step"`
 	StartCmd    string            `json:"startCommand,omitempty" jsonschema:"description=The command to run in the container"`
-	Variables   map[string]string `json:"variables,omitempty" jsonschema:"description=The variables available to this step. The key is the name of the variable that is referenced in a variable command"`
+	ReleaseCmd  string            `json:"releaseCommand,omitempty" jsonschema:"description=The command to run once before a new release is started (e.g. database migrations)"`
+	Variables   map[string]string `json:"variables,omitempty" jsonschema:"description=The environment variables available in the container"`
 	Paths       []string          `json:"paths,omitempty" jsonschema:"description=The paths to prepend to the $PATH environment variable"`
+	Processes   map[string]string `json:"processes,omitempty" jsonschema:"description=Named process types that can be run from the image (e.g. web or worker). The key is the process name and the value is the command"`
+	Ports       []string          `json:"ports,omitempty" jsonschema:"description=The ports the container listens on (e.g. 80 or 53/udp)"`
+	Healthcheck *plan.Healthcheck `json:"healthcheck,omitempty" jsonschema:"description=The command used to check that the container is healthy"`
+	StopSignal  string            `json:"stopSignal,omitempty" jsonschema:"description=The signal sent to the container to stop it (e.g. SIGTERM)"`
+	User        string            `json:"user,omitempty" jsonschema:"description=The user to run the container as. Either a user name or uid:gid. Use root to run as root"`
+	Labels      map[string]string `json:"labels,omitempty" jsonschema:"description=Labels to add to the final image. These override the labels railpack adds by default"`
+}
+
+type BaseImagesConfig struct {
+	Builder string `json:"builder,omitempty" jsonschema:"description=The image used by the steps that install packages. Defaults to ghcr.io/railwayapp/railpack-builder:latest. Can be pinned by digest (e.g. image@sha256:...)"`
+	Runtime string `json:"runtime,omitempty" jsonschema:"description=The image the final image is built on. Defaults to ghcr.io/railwayapp/railpack-runtime:latest. Can be pinned by digest (e.g. image@sha256:...)"`
 }
 
 type Config struct {
-	Provider         *string                `json:"provider" jsonschema:"description=The provider to use"`
+	Extends          []string               `json:"extends,omitempty" jsonschema:"description=Config files to extend. Paths are relative to this file and the configs are merged in order before it"`
+	Provider         *string                `json:"provider,omitempty" jsonschema:"description=The provider to use"`
 	BuildAptPackages []string               `json:"buildAptPackages,omitempty" jsonschema:"description=List of apt packages to install during the build step"`
 	Steps            map[string]*plan.Step  `json:"steps,omitempty" jsonschema:"description=Map of step names to step definitions"`
 	Deploy           *DeployConfig          `json:"deploy,omitempty" jsonschema:"description=Deploy configuration"`
 	Packages         map[string]string      `json:"packages,omitempty" jsonschema:"description=Map of package name to package version"`
 	Caches           map[string]*plan.Cache `json:"caches,omitempty" jsonschema:"description=Map of cache name to cache definitions. The cache key can be referenced in an exec command"`
 	Secrets          []string               `json:"secrets,omitempty" jsonschema:"description=Secrets that should be made available to commands that have useSecrets set to true"`
+	BaseImages       *BaseImagesConfig      `json:"baseImages,omitempty" jsonschema:"description=Override the base images used for the build and the final image (e.g. to use mirrored images)"`
+
+	// Partial configs merged over this config when their environment is selected. The schema is set in GetJsonSchema
+	Environments map[string]*Config `json:"environments,omitempty" jsonschema:"-"`
+
+	// The config file each value was set by, keyed by the path of the value (e.g. deploy.startCommand)
+	Sources map[string]string `json:"-"`
+
+	// The name of the environment that was applied
+	Environment string `json:"-"`
 }
 
 func EmptyConfig() *Config {
@@ -79,5 +102,20 @@ func GetJsonSchema() *jsonschema.Schema {
 	}
 
 	schema := r.Reflect(&Config{})
+
+	// Environments are partial configs, so they are described with a second copy of the schema.
+	// They can't extend other files or have environments of their own
+	environmentSchema := r.Reflect(&Config{})
+	environmentSchema.Version = ""
+	environmentSchema.ID = ""
+	environmentSchema.Properties.Delete("$schema")
+	environmentSchema.Properties.Delete("extends")
+
+	schema.Properties.Set("environments", &jsonschema.Schema{
+		Type:                 "object",
+		Description:          "Map of environment names (e.g. staging or production) to partial configs that are merged over this config when the environment is selected with RAILPACK_ENVIRONMENT or --environment",
+		AdditionalProperties: environmentSchema,
+	})
+
 	return schema
 }
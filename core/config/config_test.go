@@ -312,3 +312,22 @@ func TestGetJsonSchema(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, schemaJson)
 }
+
+func TestGetJsonSchemaEnvironments(t *testing.T) {
+	schema := GetJsonSchema()
+
+	environments, ok := schema.Properties.Get("environments")
+	require.True(t, ok)
+
+	environment := environments.AdditionalProperties
+	require.NotNil(t, environment)
+
+	_, hasDeploy := environment.Properties.Get("deploy")
+	require.True(t, hasDeploy)
+
+	// Environments can't extend other files or have environments of their own
+	_, hasExtends := environment.Properties.Get("extends")
+	require.False(t, hasExtends)
+	_, hasEnvironments := environment.Properties.Get("environments")
+	require.False(t, hasEnvironments)
+}
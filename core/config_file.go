@@ -0,0 +1,398 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/railpack/core/app"
+	c "github.com/railwayapp/railpack/core/config"
+	"github.com/railwayapp/railpack/core/logger"
+)
+
+// The config files Railpack looks for in the app directory, in order of precedence
+var defaultConfigFileNames = []string{
+	"railpack.json",
+	"railpack.toml",
+	"railpack.yaml",
+	"railpack.yml",
+}
+
+// findConfigFile returns the config file in the app directory with the highest precedence.
+// If there are several, a warning is logged since only one of them is used
+func findConfigFile(app *app.App, logger *logger.Logger) (string, bool) {
+	found := []string{}
+	for _, name := range defaultConfigFileNames {
+		if app.HasMatch(name) {
+			found = append(found, name)
+		}
+	}
+
+	if len(found) == 0 {
+		return "", false
+	}
+
+	if len(found) > 1 {
+		logger.LogWarn("Found multiple config files (%s). Using `%s`", strings.Join(found, ", "), found[0])
+	}
+
+	return found[0], true
+}
+
+// readConfigFile parses a JSON, TOML, or YAML config file based on its extension
+func readConfigFile(app *app.App, name string, v any) error {
+	if ext := strings.ToLower(filepath.Ext(name)); ext != ".toml" && ext != ".yaml" && ext != ".yml" {
+		return app.ReadJSON(name, v)
+	}
+
+	data, err := app.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	value, _, err := parseConfigFile(name, []byte(data))
+	if err != nil {
+		return err
+	}
+
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", name, err)
+	}
+
+	if err := json.Unmarshal(jsonBytes, v); err != nil {
+		return fmt.Errorf("error reading %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// normalizeConfigValue converts the maps decoded from YAML, which can have keys of any type, to maps with string keys
+func normalizeConfigValue(value any) (any, error) {
+	switch v := value.(type) {
+	case map[any]any:
+		normalized := make(map[string]any, len(v))
+		for key, item := range v {
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("keys must be strings, got %v", key)
+			}
+
+			n, err := normalizeConfigValue(item)
+			if err != nil {
+				return nil, err
+			}
+			normalized[keyString] = n
+		}
+		return normalized, nil
+	case map[string]any:
+		normalized := make(map[string]any, len(v))
+		for key, item := range v {
+			n, err := normalizeConfigValue(item)
+			if err != nil {
+				return nil, err
+			}
+			normalized[key] = n
+		}
+		return normalized, nil
+	case []any:
+		normalized := make([]any, len(v))
+		for i, item := range v {
+			n, err := normalizeConfigValue(item)
+			if err != nil {
+				return nil, err
+			}
+			normalized[i] = n
+		}
+		return normalized, nil
+	case []map[string]any:
+		normalized := make([]any, len(v))
+		for i, item := range v {
+			n, err := normalizeConfigValue(item)
+			if err != nil {
+				return nil, err
+			}
+			normalized[i] = n
+		}
+		return normalized, nil
+	default:
+		return value, nil
+	}
+}
+
+// configLoader loads config files and the files they extend
+type configLoader struct {
+	repo   *app.App
+	logger *logger.Logger
+
+	// Whether config errors fail the build instead of being logged as warnings
+	strict bool
+	errors []configError
+
+	// The absolute paths and names of the files currently being loaded, used to detect cycles
+	stack []string
+	names []string
+}
+
+func newConfigLoader(repo *app.App, env *app.Environment, logger *logger.Logger) *configLoader {
+	return &configLoader{repo: repo, logger: logger, strict: env.IsConfigVariableTruthy("STRICT_CONFIG")}
+}
+
+// readFile reads a config file and checks it against the config schema.
+// The schema errors are returned if the file can't be decoded, so that values of the wrong type are reported with their position
+func (l *configLoader) readFile(fileApp *app.App, name string, source string, config *c.Config) error {
+	validationErrors := validateConfigFile(fileApp, name, source)
+
+	if err := readConfigFile(fileApp, name, config); err != nil {
+		if slices.ContainsFunc(validationErrors, configError.isSchemaError) {
+			return configErrors(validationErrors)
+		}
+		return err
+	}
+
+	l.errors = append(l.errors, validationErrors...)
+	return nil
+}
+
+// reportErrors returns the errors found in the config files in strict mode, and logs them as warnings otherwise
+func (l *configLoader) reportErrors() error {
+	if len(l.errors) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(l.errors))
+	for _, err := range l.errors {
+		messages = append(messages, err.Error())
+	}
+
+	if l.strict {
+		return fmt.Errorf("config has errors:\n  %s\nUse the following schema to validate your config file: %s", strings.Join(messages, "\n  "), c.SchemaUrl)
+	}
+
+	for _, message := range messages {
+		l.logger.LogWarn("%s", message)
+	}
+	l.logger.LogWarn("Use the following schema to validate your config file: %s\nSet RAILPACK_STRICT_CONFIG=true to fail the build on config errors", c.SchemaUrl)
+
+	return nil
+}
+
+// load merges the base config, the config file of the app, and the configs it extends
+func (l *configLoader) load(env *app.Environment, options *GenerateBuildPlanOptions) (*c.Config, error) {
+	baseConfig := c.EmptyConfig()
+	if baseConfigPath, _ := env.GetConfigVariable("BASE_CONFIG"); baseConfigPath != "" {
+		config, err := l.loadBaseConfig(baseConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		baseConfig = config
+	}
+
+	config := c.EmptyConfig()
+
+	configFileName := options.ConfigFilePath
+	if envConfigFileName, _ := env.GetConfigVariable("CONFIG_FILE"); envConfigFileName != "" {
+		configFileName = envConfigFileName
+	}
+
+	if configFileName == "" {
+		defaultConfigFileName, found := findConfigFile(l.repo, l.logger)
+		if !found {
+			return baseConfig, nil
+		}
+		configFileName = defaultConfigFileName
+	} else if !l.repo.HasMatch(configFileName) {
+		l.logger.LogWarn("Config file `%s` not found", configFileName)
+		return baseConfig, nil
+	}
+
+	source := filepath.ToSlash(configFileName)
+
+	// A config file that can't be read is reported with the other config errors and ignored
+	if err := l.readFile(l.repo, configFileName, source, config); err != nil {
+		var fileErrors configErrors
+		if errors.As(err, &fileErrors) {
+			l.errors = append(l.errors, fileErrors...)
+		} else {
+			l.errors = append(l.errors, configError{file: source, err: err})
+		}
+		return baseConfig, nil
+	}
+
+	l.logger.LogInfo("Using config file `%s`", configFileName)
+	l.logger.LogWarn("The config file format is not yet finalized and subject to change.")
+
+	config, err := l.resolveExtends(l.repo, configFileName, source, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Merge(baseConfig, config), nil
+}
+
+// applyEnvironment merges the overlay of the selected environment over the config
+func (l *configLoader) applyEnvironment(config *c.Config, name string) *c.Config {
+	environments := config.Environments
+	config.Environments = nil
+
+	// Values set by overlays are only sources once their environment is applied
+	prefix := "environments." + name + "."
+	overlaySources := map[string]string{}
+	for key, source := range config.Sources {
+		if !strings.HasPrefix(key, "environments.") {
+			continue
+		}
+		if name != "" && strings.HasPrefix(key, prefix) {
+			overlaySources[strings.TrimPrefix(key, prefix)] = fmt.Sprintf("%s (%s)", source, name)
+		}
+		delete(config.Sources, key)
+	}
+
+	if name == "" {
+		return config
+	}
+
+	overlay, ok := environments[name]
+	if !ok {
+		err := fmt.Errorf("environment `%s` is not defined in the config", name)
+		if available := slices.Sorted(maps.Keys(environments)); len(available) > 0 {
+			err = fmt.Errorf("%w. Available environments: %s", err, strings.Join(available, ", "))
+		}
+		l.errors = append(l.errors, configError{err: err})
+		return config
+	}
+
+	l.logger.LogInfo("Using config environment `%s`", name)
+
+	overlay.Sources = overlaySources
+	merged := c.Merge(config, overlay)
+	merged.Environments = nil
+	merged.Environment = name
+
+	return merged
+}
+
+// loadBaseConfig loads the config file at an absolute path outside of the repo (e.g. a config shared by an organization)
+func (l *configLoader) loadBaseConfig(path string) (*c.Config, error) {
+	if !filepath.IsAbs(path) {
+		return nil, fmt.Errorf("base config `%s` must be an absolute path", path)
+	}
+
+	baseApp, err := app.NewApp(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load base config `%s`: %w", path, err)
+	}
+
+	config, err := l.loadFile(baseApp, filepath.Base(path), path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load base config `%s`: %w", path, err)
+	}
+
+	l.logger.LogInfo("Using base config `%s`", path)
+
+	return config, nil
+}
+
+func (l *configLoader) loadFile(fileApp *app.App, name string, source string) (*c.Config, error) {
+	config := c.EmptyConfig()
+	if err := l.readFile(fileApp, name, source, config); err != nil {
+		return nil, err
+	}
+
+	return l.resolveExtends(fileApp, name, source, config)
+}
+
+// resolveExtends merges the configs that a config extends before it.
+// The name of the file is relative to the app it was read from and the source is the name it is reported as
+func (l *configLoader) resolveExtends(fileApp *app.App, name string, source string, config *c.Config) (*c.Config, error) {
+	path := filepath.Join(fileApp.Source, name)
+	if i := slices.Index(l.stack, path); i != -1 {
+		cycle := append(slices.Clone(l.names[i:]), source)
+		return nil, fmt.Errorf("config files extend each other in a cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	l.stack = append(l.stack, path)
+	l.names = append(l.names, source)
+	defer func() {
+		l.stack = l.stack[:len(l.stack)-1]
+		l.names = l.names[:len(l.names)-1]
+	}()
+
+	config.Sources = getConfigSources(config, source)
+
+	configs := []*c.Config{}
+	for _, extends := range config.Extends {
+		if filepath.IsAbs(extends) {
+			return nil, fmt.Errorf("config `%s` extends `%s`: extended configs must be relative paths. Use RAILPACK_BASE_CONFIG for a config outside of the repo", source, extends)
+		}
+
+		extendsName := filepath.Join(filepath.Dir(name), extends)
+		extendsSource := filepath.Join(fileApp.Source, extendsName)
+
+		if fileApp == l.repo {
+			if !filepath.IsLocal(extendsName) {
+				return nil, fmt.Errorf("config `%s` extends `%s`: extended configs must be inside the repo", source, extends)
+			}
+			extendsSource = filepath.ToSlash(extendsName)
+		}
+
+		extendedConfig, err := l.loadFile(fileApp, extendsName, extendsSource)
+		if err != nil {
+			return nil, fmt.Errorf("config `%s` extends `%s`: %w", source, extends, err)
+		}
+
+		l.logger.LogInfo("Extending config file `%s`", extendsSource)
+		configs = append(configs, extendedConfig)
+	}
+
+	if len(configs) == 0 {
+		return config, nil
+	}
+
+	merged := c.Merge(append(configs, config)...)
+	merged.Extends = nil
+
+	return merged, nil
+}
+
+// getConfigSources maps the path of every value set in a config (e.g. deploy.startCommand or steps.build.commands) to its file.
+// Objects are walked into so that a file only claims the keys it sets, while arrays are replaced as a whole when configs are merged
+func getConfigSources(config *c.Config, source string) map[string]string {
+	sources := map[string]string{}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return sources
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return sources
+	}
+
+	delete(values, "extends")
+	addConfigSources(sources, "", values, source)
+
+	return sources
+}
+
+func addConfigSources(sources map[string]string, prefix string, value any, source string) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			addConfigSources(sources, path, v[key], source)
+		}
+	default:
+		sources[prefix] = source
+	}
+}
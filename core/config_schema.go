@@ -0,0 +1,376 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"github.com/railwayapp/railpack/core/app"
+	c "github.com/railwayapp/railpack/core/config"
+	"github.com/railwayapp/railpack/internal/utils"
+	"github.com/tailscale/hujson"
+	"gopkg.in/yaml.v3"
+)
+
+// configError is a problem with a config file, such as a syntax error or a value that does not match the config schema
+type configError struct {
+	// The file is empty for errors that are not about a single file
+	file string
+
+	// The position of the value in the file. The line is 0 if it is not known
+	line   int
+	column int
+
+	err error
+}
+
+func (e configError) Error() string {
+	if e.file == "" {
+		return e.err.Error()
+	}
+
+	location := e.file
+	if e.line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", e.file, e.line, e.column)
+	}
+	return fmt.Sprintf("%s: %s", location, e.err)
+}
+
+func (e configError) isSchemaError() bool {
+	var schemaError utils.SchemaError
+	return errors.As(e.err, &schemaError)
+}
+
+// configErrors are the errors of a config file that can't be read
+type configErrors []configError
+
+func (e configErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+type configPosition struct {
+	line   int
+	column int
+}
+
+// validateConfigFile checks a config file against the config schema.
+// Each error points to the value in the file, e.g. railpack.json:4:7: /steps/build/comands: unknown property "comands"
+func validateConfigFile(fileApp *app.App, name string, source string) []configError {
+	data, err := fileApp.ReadFile(name)
+	if err != nil {
+		return []configError{{file: source, err: err}}
+	}
+
+	value, positions, err := parseConfigFile(name, []byte(data))
+	if err != nil {
+		return []configError{{file: source, err: err}}
+	}
+
+	schemaErrors, err := utils.ValidateJSONSchema(c.GetJsonSchema(), value)
+	if err != nil || len(schemaErrors) == 0 {
+		return nil
+	}
+
+	errors := make([]configError, 0, len(schemaErrors))
+	for _, schemaError := range schemaErrors {
+		position := findConfigPosition(positions, schemaError.Pointer)
+		errors = append(errors, configError{file: source, line: position.line, column: position.column, err: schemaError})
+	}
+
+	return errors
+}
+
+// findConfigPosition returns the position of the value at a JSON pointer.
+// If the value is not in the file (e.g. a missing property) the position of the closest parent is used
+func findConfigPosition(positions map[string]configPosition, pointer string) configPosition {
+	for {
+		if position, ok := positions[pointer]; ok {
+			return position
+		}
+
+		i := strings.LastIndex(pointer, "/")
+		if i == -1 {
+			return configPosition{}
+		}
+		pointer = pointer[:i]
+	}
+}
+
+// parseConfigFile decodes a JSON, TOML, or YAML config file based on its extension into JSON values.
+// It also maps the JSON pointer of the values to their position in the file, using the same parser as the values.
+// The position of an object property is the position of its key
+func parseConfigFile(name string, data []byte) (any, map[string]configPosition, error) {
+	var (
+		raw       any
+		positions map[string]configPosition
+		err       error
+	)
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".toml":
+		if raw, positions, err = parseTOMLConfig(data); err != nil {
+			return nil, nil, fmt.Errorf("error reading %s as TOML: %w", name, err)
+		}
+	case ".yaml", ".yml":
+		if raw, positions, err = parseYAMLConfig(data); err != nil {
+			return nil, nil, fmt.Errorf("error reading %s as YAML: %w", name, err)
+		}
+	default:
+		return parseJSONConfig(name, data)
+	}
+
+	// TOML and YAML values are converted to JSON so every format supports the same values (e.g. commands and inputs as strings)
+	normalized, err := normalizeConfigValue(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading %s: %w", name, err)
+	}
+
+	jsonBytes, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading %s: %w", name, err)
+	}
+
+	var value any
+	if err := json.Unmarshal(jsonBytes, &value); err != nil {
+		return nil, nil, fmt.Errorf("error reading %s: %w", name, err)
+	}
+
+	return value, positions, nil
+}
+
+// parseJSONConfig decodes a JSON file with comments and trailing commas token by token, recording the position of each value
+func parseJSONConfig(name string, data []byte) (any, map[string]configPosition, error) {
+	// Comments and trailing commas are replaced with spaces, so the offsets match the original file
+	standardized, err := hujson.Standardize(slices.Clone(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	positions := map[string]configPosition{}
+	decoder := json.NewDecoder(bytes.NewReader(standardized))
+
+	var decode func(pointer string) (any, error)
+	decode = func(pointer string) (any, error) {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		delim, ok := token.(json.Delim)
+		if !ok {
+			return token, nil
+		}
+
+		object := map[string]any{}
+		array := []any{}
+
+		for i := 0; decoder.More(); i++ {
+			offset := nextJSONTokenOffset(standardized, decoder.InputOffset())
+
+			key := ""
+			childPointer := fmt.Sprintf("%s/%d", pointer, i)
+			if delim == '{' {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				key = fmt.Sprint(keyToken)
+				childPointer = utils.JSONPointer(pointer, key)
+			}
+
+			positions[childPointer] = offsetPosition(standardized, offset)
+			child, err := decode(childPointer)
+			if err != nil {
+				return nil, err
+			}
+
+			if delim == '{' {
+				object[key] = child
+			} else {
+				array = append(array, child)
+			}
+		}
+
+		// The closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+
+		if delim == '{' {
+			return object, nil
+		}
+		return array, nil
+	}
+
+	positions[""] = offsetPosition(standardized, nextJSONTokenOffset(standardized, 0))
+	value, err := decode("")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading %s as JSON: %w", name, err)
+	}
+
+	return value, positions, nil
+}
+
+// nextJSONTokenOffset skips the whitespace and separators before the next token
+func nextJSONTokenOffset(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.ContainsRune(" \t\r\n,:", rune(data[offset])) {
+		offset++
+	}
+	return offset
+}
+
+func offsetPosition(data []byte, offset int64) configPosition {
+	before := data[:min(offset, int64(len(data)))]
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+
+	return configPosition{
+		line:   bytes.Count(before, []byte("\n")) + 1,
+		column: utf8.RuneCount(before[lineStart:]) + 1,
+	}
+}
+
+// parseYAMLConfig decodes a YAML file into its node tree once, and reads both the values and their positions from the nodes
+func parseYAMLConfig(data []byte) (any, map[string]configPosition, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, err
+	}
+
+	positions := map[string]configPosition{}
+
+	var walk func(node *yaml.Node, pointer string)
+	walk = func(node *yaml.Node, pointer string) {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			if len(node.Content) > 0 {
+				positions[pointer] = configPosition{line: node.Content[0].Line, column: node.Content[0].Column}
+				walk(node.Content[0], pointer)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i]
+				childPointer := utils.JSONPointer(pointer, key.Value)
+				positions[childPointer] = configPosition{line: key.Line, column: key.Column}
+				walk(node.Content[i+1], childPointer)
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				childPointer := fmt.Sprintf("%s/%d", pointer, i)
+				positions[childPointer] = configPosition{line: item.Line, column: item.Column}
+				walk(item, childPointer)
+			}
+		case yaml.AliasNode:
+			if node.Alias != nil {
+				walk(node.Alias, pointer)
+			}
+		}
+	}
+
+	walk(&root, "")
+
+	// An empty file has no document
+	if len(root.Content) == 0 {
+		return nil, positions, nil
+	}
+
+	var value any
+	if err := root.Decode(&value); err != nil {
+		return nil, nil, err
+	}
+
+	return value, positions, nil
+}
+
+// parseTOMLConfig decodes a TOML file and finds the position of its keys and array items with the parser of the same library.
+// Keys defined by table headers get the position of the header
+func parseTOMLConfig(data []byte) (any, map[string]configPosition, error) {
+	var value map[string]any
+	if err := toml.Unmarshal(data, &value); err != nil {
+		return nil, nil, err
+	}
+
+	positions := map[string]configPosition{"": {line: 1, column: 1}}
+
+	nodePosition := func(node *unstable.Node, fallback configPosition) configPosition {
+		if node.Raw.Length == 0 {
+			return fallback
+		}
+		return offsetPosition(data, int64(node.Raw.Offset))
+	}
+
+	// setKey records the position of every table a dotted key defines, unless an earlier key or header defined it
+	setKey := func(table string, key unstable.Iterator) (string, configPosition) {
+		pointer := table
+		position := configPosition{}
+		for key.Next() {
+			node := key.Node()
+			pointer = utils.JSONPointer(pointer, string(node.Data))
+			position = nodePosition(node, position)
+
+			if _, exists := positions[pointer]; !exists || key.IsLast() {
+				positions[pointer] = position
+			}
+		}
+		return pointer, position
+	}
+
+	var walkValue func(node *unstable.Node, pointer string, position configPosition)
+	walkValue = func(node *unstable.Node, pointer string, position configPosition) {
+		switch node.Kind {
+		case unstable.Array:
+			children := node.Children()
+			for i := 0; children.Next(); i++ {
+				child := children.Node()
+				childPointer := fmt.Sprintf("%s/%d", pointer, i)
+				positions[childPointer] = nodePosition(child, position)
+				walkValue(child, childPointer, positions[childPointer])
+			}
+		case unstable.InlineTable:
+			children := node.Children()
+			for children.Next() {
+				keyValue := children.Node()
+				childPointer, childPosition := setKey(pointer, keyValue.Key())
+				walkValue(keyValue.Value(), childPointer, childPosition)
+			}
+		}
+	}
+
+	table := ""
+	arrayTableCounts := map[string]int{}
+
+	parser := unstable.Parser{}
+	parser.Reset(data)
+	for parser.NextExpression() {
+		expression := parser.Expression()
+
+		switch expression.Kind {
+		case unstable.Table:
+			table, _ = setKey("", expression.Key())
+		case unstable.ArrayTable:
+			pointer, position := setKey("", expression.Key())
+			table = fmt.Sprintf("%s/%d", pointer, arrayTableCounts[pointer])
+			arrayTableCounts[pointer]++
+			positions[table] = position
+		case unstable.KeyValue:
+			pointer, position := setKey(table, expression.Key())
+			walkValue(expression.Value(), pointer, position)
+		}
+	}
+
+	if err := parser.Error(); err != nil {
+		return nil, nil, err
+	}
+
+	return value, positions, nil
+}
@@ -2,12 +2,17 @@ package core
 
 import (
 	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/railwayapp/railpack/core/app"
 	"github.com/railwayapp/railpack/core/config"
+	"github.com/railwayapp/railpack/core/logger"
+	"github.com/railwayapp/railpack/core/plan"
 	"github.com/stretchr/testify/require"
 )
 
@@ -31,12 +36,14 @@ func TestGenerateConfigFromEnvironment(t *testing.T) {
 		{
 			name: "kitchen sink",
 			envVars: map[string]string{
-				"RAILPACK_INSTALL_CMD":         "npm install",
-				"RAILPACK_BUILD_CMD":           "npm run build",
-				"RAILPACK_START_CMD":           "npm start",
-				"RAILPACK_PACKAGES":            "node@18 python@3.9",
-				"RAILPACK_BUILD_APT_PACKAGES":  "build-essential libssl-dev",
-				"RAILPACK_DEPLOY_APT_PACKAGES": "libssl-dev",
+				"RAILPACK_INSTALL_CMD":                           "npm install",
+				"RAILPACK_BUILD_CMD":                             "npm run build",
+				"RAILPACK_START_CMD":                             "npm start",
+				"RAILPACK_PACKAGES":                              "node@18 python@3.9",
+				"RAILPACK_BUILD_APT_PACKAGES":                    "build-essential libssl-dev",
+				"RAILPACK_DEPLOY_APT_PACKAGES":                   "libssl-dev",
+				"RAILPACK_DEPLOY_USER":                           "1001:1001",
+				"RAILPACK_LABEL_ORG_OPENCONTAINERS_IMAGE_SOURCE": "https://github.com/railwayapp/railpack",
 			},
 			expected: `{
 				"steps": {
@@ -69,10 +76,30 @@ func TestGenerateConfigFromEnvironment(t *testing.T) {
 				"caches": {},
 				"deploy": {
 					"startCommand": "npm start",
-					"aptPackages": ["libssl-dev"]
+					"aptPackages": ["libssl-dev"],
+					"user": "1001:1001",
+					"labels": {
+						"org.opencontainers.image.source": "https://github.com/railwayapp/railpack"
+					}
 				},
 				"secrets": ["RAILPACK_BUILD_APT_PACKAGES", "RAILPACK_BUILD_CMD", "RAILPACK_DEPLOY_APT_PACKAGES",
-					"RAILPACK_INSTALL_CMD", "RAILPACK_PACKAGES", "RAILPACK_START_CMD"]
+					"RAILPACK_DEPLOY_USER", "RAILPACK_INSTALL_CMD", "RAILPACK_LABEL_ORG_OPENCONTAINERS_IMAGE_SOURCE", "RAILPACK_PACKAGES", "RAILPACK_START_CMD"]
+			}`,
+		},
+		{
+			name: "base images",
+			envVars: map[string]string{
+				"RAILPACK_RUNTIME_IMAGE": "registry.example.com/railpack-runtime@sha256:abc",
+			},
+			expected: `{
+				"steps": {},
+				"packages": {},
+				"caches": {},
+				"deploy": {},
+				"baseImages": {
+					"runtime": "registry.example.com/railpack-runtime@sha256:abc"
+				},
+				"secrets": ["RAILPACK_RUNTIME_IMAGE"]
 			}`,
 		},
 	}
@@ -92,3 +119,470 @@ func TestGenerateConfigFromEnvironment(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateConfigFromFile(t *testing.T) {
+	files := map[string]string{
+		"railpack.json": `{
+			"$schema": "https://schema.railpack.com",
+			"packages": { "node": "22" },
+			"steps": {
+				"build": {
+					"inputs": ["$install", "."],
+					"commands": ["RUN#Build:npm run build", { "path": "/app/bin" }],
+					"variables": { "NODE_ENV": "production" }
+				}
+			},
+			"deploy": { "startCommand": "npm start", "inputs": ["..."] }
+		}`,
+		"railpack.toml": `
+"$schema" = "https://schema.railpack.com"
+
+[packages]
+node = "22"
+
+[steps.build]
+inputs = ["$install", "."]
+commands = ["RUN#Build:npm run build", { path = "/app/bin" }]
+variables = { NODE_ENV = "production" }
+
+[deploy]
+startCommand = "npm start"
+inputs = ["..."]
+`,
+		"railpack.yaml": `
+$schema: https://schema.railpack.com
+packages:
+  node: "22"
+steps:
+  build:
+    inputs: [$install, .]
+    commands:
+      - "RUN#Build:npm run build"
+      - path: /app/bin
+    variables:
+      NODE_ENV: production
+deploy:
+  startCommand: npm start
+  inputs: ["..."]
+`,
+	}
+
+	readConfig := func(t *testing.T, names ...string) (*config.Config, *logger.Logger) {
+		dir := t.TempDir()
+		for _, name := range names {
+			contents := files[name]
+			if name == "railpack.yml" {
+				contents = files["railpack.yaml"]
+			}
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+		}
+
+		userApp, err := app.NewApp(dir)
+		require.NoError(t, err)
+
+		logger := logger.NewLogger()
+		cfg, err := GenerateConfigFromFile(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{}, logger)
+		require.NoError(t, err)
+		return cfg, logger
+	}
+
+	expected, _ := readConfig(t, "railpack.json")
+	require.Equal(t, "npm start", expected.Deploy.StartCmd)
+	require.Equal(t, []plan.Input{plan.NewStepInput("install"), plan.NewLocalInput(".")}, expected.Steps["build"].Inputs)
+	require.Equal(t, []plan.Command{
+		plan.NewExecShellCommand("npm run build", plan.ExecOptions{CustomName: "Build"}),
+		plan.NewPathCommand("/app/bin"),
+	}, expected.Steps["build"].Commands)
+
+	for _, name := range []string{"railpack.toml", "railpack.yaml", "railpack.yml"} {
+		t.Run(name, func(t *testing.T) {
+			cfg, logger := readConfig(t, name)
+			require.Equal(t, name, cfg.Sources["deploy.startCommand"])
+			cfg.Sources = expected.Sources
+			require.Equal(t, expected, cfg)
+			require.Equal(t, fmt.Sprintf("Using config file `%s`", name), logger.Logs[0].Msg)
+		})
+	}
+
+	t.Run("multiple config files", func(t *testing.T) {
+		cfg, logger := readConfig(t, "railpack.yaml", "railpack.toml")
+		require.Equal(t, "railpack.toml", cfg.Sources["deploy.startCommand"])
+		cfg.Sources = expected.Sources
+		require.Equal(t, expected, cfg)
+		require.Equal(t, "Found multiple config files (railpack.toml, railpack.yaml). Using `railpack.toml`", logger.Logs[0].Msg)
+		require.Equal(t, "Using config file `railpack.toml`", logger.Logs[1].Msg)
+	})
+}
+
+func TestGenerateConfigFromFileExtends(t *testing.T) {
+	readConfig := func(t *testing.T, files map[string]string, variables map[string]string) (*config.Config, error) {
+		dir := t.TempDir()
+		for name, contents := range files {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+		}
+
+		userApp, err := app.NewApp(dir)
+		require.NoError(t, err)
+
+		return GenerateConfigFromFile(userApp, app.NewEnvironment(&variables), &GenerateBuildPlanOptions{}, logger.NewLogger())
+	}
+
+	t.Run("merges extended configs before the file", func(t *testing.T) {
+		cfg, err := readConfig(t, map[string]string{
+			"railpack.json": `{
+				"extends": ["configs/base.json", "configs/node.toml"],
+				"deploy": { "startCommand": "npm start" }
+			}`,
+			"configs/base.json": `{
+				"extends": ["shared.yaml"],
+				"deploy": { "startCommand": "./start.sh", "variables": { "HELLO": "base" } }
+			}`,
+			"configs/shared.yaml": `
+buildAptPackages: [git]
+deploy:
+  variables:
+    HELLO: shared
+    SHARED: "true"
+`,
+			"configs/node.toml": `
+[packages]
+node = "22"
+`,
+		}, nil)
+		require.NoError(t, err)
+
+		require.Nil(t, cfg.Extends)
+		require.Equal(t, "npm start", cfg.Deploy.StartCmd)
+		require.Equal(t, map[string]string{"HELLO": "base", "SHARED": "true"}, cfg.Deploy.Variables)
+		require.Equal(t, map[string]string{"node": "22"}, cfg.Packages)
+		require.Equal(t, []string{"git"}, cfg.BuildAptPackages)
+
+		require.Equal(t, map[string]string{
+			"buildAptPackages":        "configs/shared.yaml",
+			"deploy.startCommand":     "railpack.json",
+			"deploy.variables.HELLO":  "configs/base.json",
+			"deploy.variables.SHARED": "configs/shared.yaml",
+			"packages.node":           "configs/node.toml",
+		}, cfg.Sources)
+	})
+
+	t.Run("base config", func(t *testing.T) {
+		baseDir := t.TempDir()
+		basePath := filepath.Join(baseDir, "railpack.base.json")
+		require.NoError(t, os.WriteFile(basePath, []byte(`{ "extends": ["../shared.json"], "packages": { "node": "20" } }`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(baseDir), "shared.json"), []byte(`{ "packages": { "python": "3.13" } }`), 0644))
+
+		cfg, err := readConfig(t, map[string]string{
+			"railpack.json": `{ "packages": { "node": "22" } }`,
+		}, map[string]string{"RAILPACK_BASE_CONFIG": basePath})
+		require.NoError(t, err)
+
+		require.Equal(t, map[string]string{"node": "22", "python": "3.13"}, cfg.Packages)
+		require.Equal(t, map[string]string{
+			"packages.node":   "railpack.json",
+			"packages.python": filepath.Join(filepath.Dir(baseDir), "shared.json"),
+		}, cfg.Sources)
+	})
+
+	t.Run("base config without a config file", func(t *testing.T) {
+		basePath := filepath.Join(t.TempDir(), "railpack.json")
+		require.NoError(t, os.WriteFile(basePath, []byte(`{ "packages": { "node": "20" } }`), 0644))
+
+		cfg, err := readConfig(t, map[string]string{}, map[string]string{"RAILPACK_BASE_CONFIG": basePath})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"node": "20"}, cfg.Packages)
+		require.Equal(t, map[string]string{"packages.node": basePath}, cfg.Sources)
+	})
+
+	errorTests := []struct {
+		name      string
+		files     map[string]string
+		variables map[string]string
+		err       string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"railpack.json":  `{ "extends": ["a.json"] }`,
+				"a.json":         `{ "extends": ["nested/b.json"] }`,
+				"nested/b.json":  `{ "extends": ["../railpack.json"] }`,
+				"unrelated.json": `{}`,
+			},
+			err: "config files extend each other in a cycle: railpack.json -> a.json -> nested/b.json -> railpack.json",
+		},
+		{
+			name:  "self",
+			files: map[string]string{"railpack.json": `{ "extends": ["./railpack.json"] }`},
+			err:   "config files extend each other in a cycle: railpack.json -> railpack.json",
+		},
+		{
+			name:  "missing file",
+			files: map[string]string{"railpack.json": `{ "extends": ["missing.json"] }`},
+			err:   "config `railpack.json` extends `missing.json`: error reading missing.json",
+		},
+		{
+			name:  "outside of the repo",
+			files: map[string]string{"railpack.json": `{ "extends": ["../base.json"] }`},
+			err:   "config `railpack.json` extends `../base.json`: extended configs must be inside the repo",
+		},
+		{
+			name:  "absolute path",
+			files: map[string]string{"railpack.json": `{ "extends": ["/etc/railpack.json"] }`},
+			err:   "extended configs must be relative paths. Use RAILPACK_BASE_CONFIG for a config outside of the repo",
+		},
+		{
+			name:      "relative base config",
+			files:     map[string]string{},
+			variables: map[string]string{"RAILPACK_BASE_CONFIG": "base.json"},
+			err:       "base config `base.json` must be an absolute path",
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readConfig(t, tt.files, tt.variables)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestValidateConfigFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{
+			name: "railpack.json",
+			contents: `{
+  // Comments are allowed
+  "steps": {
+    "build": {
+      "comands": ["npm run build"],
+      "inputs": ["$install", 1],
+    },
+  },
+  "deploy": { "startComand": "npm start" }
+}`,
+			want: []string{
+				`railpack.json:9:15: /deploy/startComand: unknown property "startComand". Did you mean "startCommand"?`,
+				`railpack.json:5:7: /steps/build/comands: unknown property "comands". Did you mean "commands"?`,
+				`railpack.json:6:30: /steps/build/inputs/1: value does not match any of the allowed formats`,
+			},
+		},
+		{
+			name: "railpack.toml",
+			contents: `packages = { node = 22 }
+
+[steps.build]
+comands = ["npm run build"]
+inputs = ["$install", 1]
+
+[deploy]
+startComand = "npm start"
+variables.PORT = 3000
+`,
+			want: []string{
+				`railpack.toml:8:1: /deploy/startComand: unknown property "startComand". Did you mean "startCommand"?`,
+				`railpack.toml:9:11: /deploy/variables/PORT: expected string but got integer`,
+				`railpack.toml:1:14: /packages/node: expected string but got integer`,
+				`railpack.toml:4:1: /steps/build/comands: unknown property "comands". Did you mean "commands"?`,
+				`railpack.toml:5:23: /steps/build/inputs/1: value does not match any of the allowed formats`,
+			},
+		},
+		{
+			name: "railpack.yaml",
+			contents: `steps:
+  build:
+    comands:
+      - npm run build
+deploy:
+  startComand: npm start
+  variables:
+    PORT: 3000
+environments:
+  staging:
+    deploy:
+      startComand: npm run dev
+`,
+			want: []string{
+				`railpack.yaml:6:3: /deploy/startComand: unknown property "startComand". Did you mean "startCommand"?`,
+				`railpack.yaml:8:5: /deploy/variables/PORT: expected string but got integer`,
+				`railpack.yaml:12:7: /environments/staging/deploy/startComand: unknown property "startComand". Did you mean "startCommand"?`,
+				`railpack.yaml:3:5: /steps/build/comands: unknown property "comands". Did you mean "commands"?`,
+			},
+		},
+		{
+			name:     "railpack.json",
+			contents: `{ "steps": { "build": { "commands": ["npm run build"] } }`,
+			want:     []string{"railpack.json: hujson: line 1, column 58: parsing object after value: unexpected EOF"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, tt.name), []byte(tt.contents), 0644))
+
+			userApp, err := app.NewApp(dir)
+			require.NoError(t, err)
+
+			got := []string{}
+			for _, err := range validateConfigFile(userApp, tt.name, tt.name) {
+				got = append(got, err.Error())
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGenerateConfigFromFileStrict(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "railpack.json"), []byte(`{
+  "packages": { "node": "22" },
+  "deploy": { "startComand": "npm start" }
+}`), 0644))
+
+	userApp, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	t.Run("warns by default", func(t *testing.T) {
+		log := logger.NewLogger()
+		cfg, err := GenerateConfigFromFile(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{}, log)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"node": "22"}, cfg.Packages)
+
+		messages := []string{}
+		for _, msg := range log.Logs {
+			if msg.Level == logger.Warn {
+				messages = append(messages, msg.Msg)
+			}
+		}
+		require.Contains(t, messages, `railpack.json:3:15: /deploy/startComand: unknown property "startComand". Did you mean "startCommand"?`)
+	})
+
+	t.Run("fails in strict mode", func(t *testing.T) {
+		env := app.NewEnvironment(&map[string]string{"RAILPACK_STRICT_CONFIG": "true"})
+		_, err := GenerateConfigFromFile(userApp, env, &GenerateBuildPlanOptions{}, logger.NewLogger())
+		require.ErrorContains(t, err, "config has errors:\n  railpack.json:3:15: /deploy/startComand: unknown property \"startComand\"")
+	})
+
+	t.Run("fails in strict mode when the file can't be parsed", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "railpack.yaml"), []byte("steps: [\n"), 0644))
+
+		userApp, err := app.NewApp(dir)
+		require.NoError(t, err)
+
+		env := app.NewEnvironment(&map[string]string{"RAILPACK_STRICT_CONFIG": "1"})
+		_, err = GenerateConfigFromFile(userApp, env, &GenerateBuildPlanOptions{}, logger.NewLogger())
+		require.ErrorContains(t, err, "config has errors:\n  railpack.yaml: ")
+	})
+
+	t.Run("reports the position of values with the wrong type", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "railpack.yaml"), []byte("packages:\n  python: 3.10\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "base.yaml"), []byte("packages:\n  python: 3.10\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "railpack.json"), []byte(`{ "extends": ["base.yaml"] }`), 0644))
+
+		userApp, err := app.NewApp(dir)
+		require.NoError(t, err)
+
+		env := app.NewEnvironment(&map[string]string{"RAILPACK_STRICT_CONFIG": "true", "RAILPACK_CONFIG_FILE": "railpack.yaml"})
+		_, err = GenerateConfigFromFile(userApp, env, &GenerateBuildPlanOptions{}, logger.NewLogger())
+		require.EqualError(t, err, "config has errors:\n  railpack.yaml:2:3: /packages/python: expected string but got number\nUse the following schema to validate your config file: "+config.SchemaUrl)
+
+		_, err = GenerateConfigFromFile(userApp, app.NewEnvironment(nil), &GenerateBuildPlanOptions{}, logger.NewLogger())
+		require.ErrorContains(t, err, "config `railpack.json` extends `base.yaml`: base.yaml:2:3: /packages/python: expected string but got number")
+	})
+}
+
+func TestGenerateConfigFromFileEnvironments(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.json"), []byte(`{
+  "environments": {
+    "production": { "deploy": { "variables": { "LOG_LEVEL": "warn" } } }
+  }
+}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "railpack.json"), []byte(`{
+  "extends": ["base.json"],
+  "deploy": { "startCommand": "node server.js", "variables": { "LOG_LEVEL": "info" } },
+  "environments": {
+    "staging": { "deploy": { "startCommand": "node --inspect server.js" } },
+    "production": { "buildAptPackages": ["curl"] }
+  }
+}`), 0644))
+
+	userApp, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	readConfig := func(t *testing.T, environment string, variables map[string]string) (*config.Config, *logger.Logger) {
+		log := logger.NewLogger()
+		cfg, err := GenerateConfigFromFile(userApp, app.NewEnvironment(&variables), &GenerateBuildPlanOptions{Environment: environment}, log)
+		require.NoError(t, err)
+		return cfg, log
+	}
+
+	t.Run("no environment", func(t *testing.T) {
+		cfg, _ := readConfig(t, "", nil)
+		require.Equal(t, "", cfg.Environment)
+		require.Nil(t, cfg.Environments)
+		require.Equal(t, "node server.js", cfg.Deploy.StartCmd)
+		require.Nil(t, cfg.BuildAptPackages)
+		require.Equal(t, map[string]string{
+			"deploy.startCommand":        "railpack.json",
+			"deploy.variables.LOG_LEVEL": "railpack.json",
+		}, cfg.Sources)
+	})
+
+	t.Run("environment option", func(t *testing.T) {
+		cfg, log := readConfig(t, "staging", nil)
+		require.Equal(t, "staging", cfg.Environment)
+		require.Equal(t, "node --inspect server.js", cfg.Deploy.StartCmd)
+		require.Equal(t, map[string]string{"LOG_LEVEL": "info"}, cfg.Deploy.Variables)
+		require.Equal(t, "railpack.json (staging)", cfg.Sources["deploy.startCommand"])
+		require.Equal(t, "Using config environment `staging`", log.Logs[len(log.Logs)-1].Msg)
+	})
+
+	t.Run("environment variable", func(t *testing.T) {
+		cfg, _ := readConfig(t, "staging", map[string]string{"RAILPACK_ENVIRONMENT": "production"})
+		require.Equal(t, "production", cfg.Environment)
+		require.Equal(t, "node server.js", cfg.Deploy.StartCmd)
+		require.Equal(t, []string{"curl"}, cfg.BuildAptPackages)
+		require.Equal(t, map[string]string{"LOG_LEVEL": "warn"}, cfg.Deploy.Variables)
+		require.Equal(t, map[string]string{
+			"buildAptPackages":           "railpack.json (production)",
+			"deploy.startCommand":        "railpack.json",
+			"deploy.variables.LOG_LEVEL": "base.json (production)",
+		}, cfg.Sources)
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		cfg, log := readConfig(t, "prod", nil)
+		require.Equal(t, "", cfg.Environment)
+		require.Equal(t, "node server.js", cfg.Deploy.StartCmd)
+		require.Equal(t, "environment `prod` is not defined in the config. Available environments: production, staging", log.Logs[len(log.Logs)-2].Msg)
+	})
+
+	t.Run("unknown environment in strict mode", func(t *testing.T) {
+		env := app.NewEnvironment(&map[string]string{"RAILPACK_STRICT_CONFIG": "true"})
+		_, err := GenerateConfigFromFile(userApp, env, &GenerateBuildPlanOptions{Environment: "prod"}, logger.NewLogger())
+		require.ErrorContains(t, err, "config has errors:\n  environment `prod` is not defined in the config. Available environments: production, staging")
+	})
+}
+
+func TestGetLabelsFromEnvironment(t *testing.T) {
+	env := app.NewEnvironment(&map[string]string{
+		"RAILPACK_LABELS":                                "com.example.Team=web, com.example.build_id = 1 ,invalid",
+		"RAILPACK_LABEL_ORG_OPENCONTAINERS_IMAGE_SOURCE": "https://github.com/railwayapp/railpack",
+		"RAILPACK_LABEL_COM_EXAMPLE_BUILD__ID":           "2",
+	})
+
+	require.Equal(t, map[string]string{
+		"com.example.Team":                "web",
+		"com.example.build_id":            "2",
+		"org.opencontainers.image.source": "https://github.com/railwayapp/railpack",
+	}, getLabelsFromEnvironment(env))
+}
@@ -17,16 +17,13 @@ import (
 	"github.com/railwayapp/railpack/internal/utils"
 )
 
-const (
-	defaultConfigFileName = "railpack.json"
-)
-
 type GenerateBuildPlanOptions struct {
 	RailpackVersion          string
 	BuildCommand             string
 	StartCommand             string
 	PreviousVersions         map[string]string
 	ConfigFilePath           string
+	Environment              string
 	ErrorMissingStartCommand bool
 }
 
@@ -36,6 +33,12 @@ type BuildResult struct {
 	ResolvedPackages  map[string]*resolver.ResolvedPackage `json:"resolvedPackages,omitempty"`
 	Metadata          map[string]string                    `json:"metadata,omitempty"`
 	DetectedProviders []string                             `json:"detectedProviders,omitempty"`
+	Processes         map[string]string                    `json:"processes,omitempty"`
+	ReleaseCmd        string                               `json:"releaseCommand,omitempty"`
+	ConfigSources     map[string]string                    `json:"configSources,omitempty"`
+	BuilderImage      string                               `json:"builderImage,omitempty"`
+	RuntimeImage      string                               `json:"runtimeImage,omitempty"`
+	Environment       string                               `json:"environment,omitempty"`
 	Logs              []logger.Msg                         `json:"logs,omitempty"`
 	Success           bool                                 `json:"success,omitempty"`
 }
@@ -66,6 +69,7 @@ func GenerateBuildPlan(app *app.App, env *app.Environment, options *GenerateBuil
 	// Figure out what providers to use
 	providerToUse, detectedProviderName := getProviders(ctx, config)
 	ctx.Metadata.Set("providers", detectedProviderName)
+	setDefaultLabels(ctx, options, detectedProviderName)
 
 	// TODO: We should indicate if we have packages specified in the config
 	// so that providers can determine if they should include mise in the final image (e.g. for shell script)
@@ -104,6 +108,12 @@ func GenerateBuildPlan(app *app.App, env *app.Environment, options *GenerateBuil
 		ResolvedPackages:  resolvedPackages,
 		Metadata:          ctx.Metadata.Properties,
 		DetectedProviders: []string{detectedProviderName},
+		Processes:         buildPlan.Deploy.Processes,
+		ReleaseCmd:        buildPlan.Deploy.ReleaseCmd,
+		ConfigSources:     config.Sources,
+		BuilderImage:      ctx.BuilderImage(),
+		RuntimeImage:      ctx.RuntimeImage(),
+		Environment:       config.Environment,
 		Logs:              logger.Logs,
 		Success:           true,
 	}
@@ -127,35 +137,29 @@ func GetConfig(app *app.App, env *app.Environment, options *GenerateBuildPlanOpt
 	return mergedConfig, nil
 }
 
-// GenerateConfigFromFile generates a config from the config file
+// GenerateConfigFromFile generates a config from the config file.
+// The file can be JSON, TOML, or YAML. If no file is specified, the first of railpack.json, railpack.toml, railpack.yaml,
+// and railpack.yml in the app directory is used.
+// The configs the file extends, and the base config set with RAILPACK_BASE_CONFIG, are merged before it.
+// The overlay of the environment selected with RAILPACK_ENVIRONMENT is merged after it
 func GenerateConfigFromFile(app *app.App, env *app.Environment, options *GenerateBuildPlanOptions, logger *logger.Logger) (*c.Config, error) {
-	config := c.EmptyConfig()
-
-	configFileName := defaultConfigFileName
-	if options.ConfigFilePath != "" {
-		configFileName = options.ConfigFilePath
-	}
+	loader := newConfigLoader(app, env, logger)
 
-	if envConfigFileName, _ := env.GetConfigVariable("CONFIG_FILE"); envConfigFileName != "" {
-		configFileName = envConfigFileName
+	config, err := loader.load(env, options)
+	if err != nil {
+		return nil, err
 	}
 
-	if !app.HasMatch(configFileName) {
-		if configFileName != defaultConfigFileName {
-			logger.LogWarn("Config file `%s` not found", configFileName)
-		}
-
-		return config, nil
+	environment := options.Environment
+	if envEnvironment, _ := env.GetConfigVariable("ENVIRONMENT"); envEnvironment != "" {
+		environment = envEnvironment
 	}
+	config = loader.applyEnvironment(config, environment)
 
-	if err := app.ReadJSON(configFileName, config); err != nil {
-		logger.LogWarn("Failed to read config file `%s`\nUse the following schema to validate your config file: %s\n", configFileName, c.SchemaUrl)
-		return config, nil
+	if err := loader.reportErrors(); err != nil {
+		return nil, err
 	}
 
-	logger.LogInfo("Using config file `%s`", configFileName)
-	logger.LogWarn("The config file format is not yet finalized and subject to change.")
-
 	return config, nil
 }
 
@@ -187,6 +191,10 @@ func GenerateConfigFromEnvironment(env *app.Environment) *c.Config {
 		config.Deploy.StartCmd = startCmdVar
 	}
 
+	if releaseCmdVar, _ := env.GetConfigVariable("RELEASE_CMD"); releaseCmdVar != "" {
+		config.Deploy.ReleaseCmd = releaseCmdVar
+	}
+
 	if envPackages, _ := env.GetConfigVariable("PACKAGES"); envPackages != "" {
 		config.Packages = utils.ParsePackageWithVersion(strings.Split(envPackages, " "))
 	}
@@ -199,6 +207,20 @@ func GenerateConfigFromEnvironment(env *app.Environment) *c.Config {
 		config.Deploy.AptPackages = strings.Split(envAptPackages, " ")
 	}
 
+	if deployUser, _ := env.GetConfigVariable("DEPLOY_USER"); deployUser != "" {
+		config.Deploy.User = deployUser
+	}
+
+	if labels := getLabelsFromEnvironment(env); len(labels) > 0 {
+		config.Deploy.Labels = labels
+	}
+
+	builderImage, _ := env.GetConfigVariable("BUILDER_IMAGE")
+	runtimeImage, _ := env.GetConfigVariable("RUNTIME_IMAGE")
+	if builderImage != "" || runtimeImage != "" {
+		config.BaseImages = &c.BaseImagesConfig{Builder: builderImage, Runtime: runtimeImage}
+	}
+
 	config.Secrets = append(config.Secrets, slices.Sorted(maps.Keys(env.Variables))...)
 
 	return config
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gkampitakis/go-snaps/snaps"
 	"github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/plan"
 	"github.com/stretchr/testify/require"
 )
 
@@ -59,3 +60,18 @@ func TestGenerateBuildPlanForExamples(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateBuildPlanBaseImages(t *testing.T) {
+	userApp, err := app.NewApp("../examples/node-npm")
+	require.NoError(t, err)
+
+	env := app.NewEnvironment(&map[string]string{
+		"RAILPACK_BUILDER_IMAGE": "registry.example.com/railpack-builder:v1",
+	})
+	buildResult := GenerateBuildPlan(userApp, env, &GenerateBuildPlanOptions{})
+	require.True(t, buildResult.Success, buildResult.Logs)
+
+	// The images are included so that the plan can be converted without the config (e.g. to a Dockerfile)
+	require.Equal(t, "registry.example.com/railpack-builder:v1", buildResult.BuilderImage)
+	require.Equal(t, plan.RAILPACK_RUNTIME_IMAGE, buildResult.RuntimeImage)
+}
@@ -0,0 +1,287 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/railwayapp/railpack/core/resolver"
+)
+
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "changed"
+)
+
+// Change is a single semantic difference between two build results
+type Change struct {
+	// The location of the change (e.g. steps.install.commands[1] or deploy.startCommand)
+	Path string     `json:"path"`
+	Type ChangeType `json:"type"`
+	Old  string     `json:"old,omitempty"`
+	New  string     `json:"new,omitempty"`
+}
+
+// DiffBuildResults compares two build results and returns the changes needed to go from a to b.
+// Plans are only compared if both results have one, and resolved packages only if neither result was read from a plain plan
+func DiffBuildResults(a, b *BuildResult) []Change {
+	d := &differ{}
+
+	// Results read from a plan file only have a plan, so there is nothing to compare against
+	if hasResultMetadata(a) && hasResultMetadata(b) {
+		d.value("railpackVersion", a.RailpackVersion, b.RailpackVersion)
+		d.list("detectedProviders", a.DetectedProviders, b.DetectedProviders)
+		d.stringMap("packages", resolvedVersions(a.ResolvedPackages), resolvedVersions(b.ResolvedPackages))
+	}
+
+	if a.Plan != nil && b.Plan != nil {
+		d.plan(a.Plan, b.Plan)
+	}
+
+	return d.changes
+}
+
+func hasResultMetadata(br *BuildResult) bool {
+	return br.RailpackVersion != "" || len(br.DetectedProviders) > 0 || len(br.ResolvedPackages) > 0
+}
+
+// DiffBuildPlans compares two build plans and returns the changes needed to go from a to b
+func DiffBuildPlans(a, b *plan.BuildPlan) []Change {
+	d := &differ{}
+	d.plan(a, b)
+	return d.changes
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) plan(a, b *plan.BuildPlan) {
+	d.value("version", versionString(a.Version), versionString(b.Version))
+
+	d.steps(a.Steps, b.Steps)
+
+	d.stringMap("caches", cacheDescriptions(a.Caches), cacheDescriptions(b.Caches))
+	d.set("secrets", a.Secrets, b.Secrets)
+
+	d.deploy(&a.Deploy, &b.Deploy)
+}
+
+func (d *differ) steps(a, b []plan.Step) {
+	stepsA := make(map[string]*plan.Step, len(a))
+	for i := range a {
+		stepsA[a[i].Name] = &a[i]
+	}
+
+	stepsB := make(map[string]*plan.Step, len(b))
+	for i := range b {
+		stepsB[b[i].Name] = &b[i]
+	}
+
+	for i := range a {
+		stepA := &a[i]
+		path := "steps." + stepA.Name
+
+		stepB, ok := stepsB[stepA.Name]
+		if !ok {
+			d.add(path, ChangeRemoved, summarizeStep(stepA), "")
+			continue
+		}
+
+		d.list(path+".inputs", inputStrings(stepA.Inputs), inputStrings(stepB.Inputs))
+		d.list(path+".commands", commandStrings(stepA.Commands), commandStrings(stepB.Commands))
+		d.set(path+".secrets", stepA.Secrets, stepB.Secrets)
+		d.set(path+".caches", stepA.Caches, stepB.Caches)
+		d.stringMap(path+".variables", stepA.Variables, stepB.Variables)
+		d.stringMap(path+".assets", stepA.Assets, stepB.Assets)
+	}
+
+	for i := range b {
+		if _, ok := stepsA[b[i].Name]; !ok {
+			d.add("steps."+b[i].Name, ChangeAdded, "", summarizeStep(&b[i]))
+		}
+	}
+}
+
+func (d *differ) deploy(a, b *plan.Deploy) {
+	d.list("deploy.inputs", inputStrings(a.Inputs), inputStrings(b.Inputs))
+	d.value("deploy.startCommand", a.StartCmd, b.StartCmd)
+	d.value("deploy.releaseCommand", a.ReleaseCmd, b.ReleaseCmd)
+	d.stringMap("deploy.variables", a.Variables, b.Variables)
+	d.list("deploy.paths", a.Paths, b.Paths)
+	d.stringMap("deploy.processes", a.Processes, b.Processes)
+	d.set("deploy.ports", a.Ports, b.Ports)
+	d.value("deploy.healthcheck", jsonString(a.Healthcheck), jsonString(b.Healthcheck))
+	d.value("deploy.stopSignal", a.StopSignal, b.StopSignal)
+	d.value("deploy.user", a.User, b.User)
+	d.stringMap("deploy.labels", a.Labels, b.Labels)
+}
+
+func (d *differ) add(path string, changeType ChangeType, old, new string) {
+	d.changes = append(d.changes, Change{Path: path, Type: changeType, Old: old, New: new})
+}
+
+// value compares two scalar values
+func (d *differ) value(path string, a, b string) {
+	switch {
+	case a == b:
+	case a == "":
+		d.add(path, ChangeAdded, "", b)
+	case b == "":
+		d.add(path, ChangeRemoved, a, "")
+	default:
+		d.add(path, ChangeModified, a, b)
+	}
+}
+
+// stringMap compares two maps key by key
+func (d *differ) stringMap(path string, a, b map[string]string) {
+	keys := slices.Collect(maps.Keys(a))
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		valueA, inA := a[key]
+		valueB, inB := b[key]
+		keyPath := fmt.Sprintf("%s.%s", path, key)
+
+		switch {
+		case !inA:
+			d.add(keyPath, ChangeAdded, "", valueB)
+		case !inB:
+			d.add(keyPath, ChangeRemoved, valueA, "")
+		case valueA != valueB:
+			d.add(keyPath, ChangeModified, valueA, valueB)
+		}
+	}
+}
+
+// set compares two lists where the order does not matter
+func (d *differ) set(path string, a, b []string) {
+	for _, value := range slices.Sorted(slices.Values(a)) {
+		if !slices.Contains(b, value) {
+			d.add(path, ChangeRemoved, value, "")
+		}
+	}
+
+	for _, value := range slices.Sorted(slices.Values(b)) {
+		if !slices.Contains(a, value) {
+			d.add(path, ChangeAdded, "", value)
+		}
+	}
+}
+
+// list compares two ordered lists using their longest common subsequence.
+// Removed items are reported at their index in a and added items at their index in b
+func (d *differ) list(path string, a, b []string) {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			d.add(fmt.Sprintf("%s[%d]", path, i), ChangeRemoved, a[i], "")
+			i++
+		default:
+			d.add(fmt.Sprintf("%s[%d]", path, j), ChangeAdded, "", b[j])
+			j++
+		}
+	}
+}
+
+func versionString(version int) string {
+	if version == 0 {
+		return ""
+	}
+	return strconv.Itoa(version)
+}
+
+func summarizeStep(step *plan.Step) string {
+	return strings.Join(commandStrings(step.Commands), "\n")
+}
+
+func inputStrings(inputs []plan.Input) []string {
+	result := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		result = append(result, input.String())
+	}
+	return result
+}
+
+func commandStrings(commands []plan.Command) []string {
+	result := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		result = append(result, jsonString(cmd))
+	}
+	return result
+}
+
+func cacheDescriptions(caches map[string]*plan.Cache) map[string]string {
+	result := make(map[string]string, len(caches))
+	for name, cache := range caches {
+		result[name] = jsonString(cache)
+	}
+	return result
+}
+
+func resolvedVersions(packages map[string]*resolver.ResolvedPackage) map[string]string {
+	result := make(map[string]string, len(packages))
+	for name, pkg := range packages {
+		switch {
+		case pkg.ResolvedVersion != nil:
+			result[name] = *pkg.ResolvedVersion
+		case pkg.RequestedVersion != nil:
+			result[name] = *pkg.RequestedVersion
+		default:
+			result[name] = ""
+		}
+	}
+	return result
+}
+
+func jsonString(v any) string {
+	if v == nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return ""
+	}
+
+	encoded := strings.TrimSpace(buf.String())
+	if encoded == "null" {
+		return ""
+	}
+	return encoded
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/railwayapp/railpack/core/resolver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffBuildPlans(t *testing.T) {
+	newPlan := func(commands ...string) *plan.BuildPlan {
+		buildPlan := plan.NewBuildPlan()
+		step := plan.NewStep("install")
+		for _, cmd := range commands {
+			step.AddCommands([]plan.Command{plan.NewExecCommand(cmd)})
+		}
+		buildPlan.AddStep(*step)
+		buildPlan.Deploy.StartCmd = "npm start"
+		return buildPlan
+	}
+
+	t.Run("no differences", func(t *testing.T) {
+		require.Empty(t, DiffBuildPlans(newPlan("npm ci"), newPlan("npm ci")))
+	})
+
+	t.Run("changed commands", func(t *testing.T) {
+		changes := DiffBuildPlans(newPlan("npm ci", "npm run build"), newPlan("npm ci", "npm test", "npm run build"))
+		require.Equal(t, []Change{
+			{Path: "steps.install.commands[1]", Type: ChangeAdded, New: `{"cmd":"npm test"}`},
+		}, changes)
+
+		changes = DiffBuildPlans(newPlan("npm ci"), newPlan("pnpm install"))
+		require.Equal(t, []Change{
+			{Path: "steps.install.commands[0]", Type: ChangeRemoved, Old: `{"cmd":"npm ci"}`},
+			{Path: "steps.install.commands[0]", Type: ChangeAdded, New: `{"cmd":"pnpm install"}`},
+		}, changes)
+	})
+
+	t.Run("added and removed steps", func(t *testing.T) {
+		before := newPlan("npm ci")
+		after := newPlan("npm ci")
+		after.Steps[0].Name = "setup"
+
+		changes := DiffBuildPlans(before, after)
+		require.Equal(t, []Change{
+			{Path: "steps.install", Type: ChangeRemoved, Old: `{"cmd":"npm ci"}`},
+			{Path: "steps.setup", Type: ChangeAdded, New: `{"cmd":"npm ci"}`},
+		}, changes)
+	})
+
+	t.Run("deploy", func(t *testing.T) {
+		before := newPlan()
+		after := newPlan()
+		after.Deploy.StartCmd = "node server.js"
+		after.Deploy.Variables = map[string]string{"NODE_ENV": "production"}
+		after.Deploy.Ports = []string{"3000/tcp"}
+
+		changes := DiffBuildPlans(before, after)
+		require.Equal(t, []Change{
+			{Path: "deploy.startCommand", Type: ChangeModified, Old: "npm start", New: "node server.js"},
+			{Path: "deploy.variables.NODE_ENV", Type: ChangeAdded, New: "production"},
+			{Path: "deploy.ports", Type: ChangeAdded, New: "3000/tcp"},
+		}, changes)
+	})
+}
+
+func TestDiffBuildResults(t *testing.T) {
+	version := func(v string) *string { return &v }
+
+	before := &BuildResult{
+		RailpackVersion: "0.1.0",
+		ResolvedPackages: map[string]*resolver.ResolvedPackage{
+			"node": {Name: "node", RequestedVersion: version("22"), ResolvedVersion: version("22.1.0")},
+			"bun":  {Name: "bun", ResolvedVersion: version("1.1.0")},
+		},
+	}
+
+	after := &BuildResult{
+		RailpackVersion: "0.2.0",
+		ResolvedPackages: map[string]*resolver.ResolvedPackage{
+			"node": {Name: "node", RequestedVersion: version("22"), ResolvedVersion: version("22.2.0")},
+			"bun":  {Name: "bun", ResolvedVersion: version("1.1.0")},
+		},
+	}
+
+	require.Equal(t, []Change{
+		{Path: "railpackVersion", Type: ChangeModified, Old: "0.1.0", New: "0.2.0"},
+		{Path: "packages.node", Type: ChangeModified, Old: "22.1.0", New: "22.2.0"},
+	}, DiffBuildResults(before, after))
+
+	t.Run("plan only", func(t *testing.T) {
+		require.Empty(t, DiffBuildResults(before, &BuildResult{Plan: plan.NewBuildPlan()}))
+	})
+}
@@ -3,6 +3,7 @@ package generate
 import (
 	"fmt"
 	"maps"
+	"path"
 	"slices"
 	"sort"
 	"strings"
@@ -158,9 +159,27 @@ func (c *GenerateContext) Generate() (*plan.BuildPlan, map[string]*resolver.Reso
 	buildPlan.Secrets = utils.RemoveDuplicates(c.Secrets)
 	buildPlan.Deploy = c.Deploy.Build()
 
+	interpolateVariables(buildPlan, c.Logger)
+
 	return buildPlan, resolvedPackages, nil
 }
 
+// BuilderImage is the image used by the steps that install packages. It can be overridden with baseImages.builder in the config
+func (c *GenerateContext) BuilderImage() string {
+	if c.Config.BaseImages != nil && c.Config.BaseImages.Builder != "" {
+		return c.Config.BaseImages.Builder
+	}
+	return plan.RAILPACK_BUILDER_IMAGE
+}
+
+// RuntimeImage is the image the final image is built on. It can be overridden with baseImages.runtime in the config
+func (c *GenerateContext) RuntimeImage() string {
+	if c.Config.BaseImages != nil && c.Config.BaseImages.Runtime != "" {
+		return c.Config.BaseImages.Runtime
+	}
+	return plan.RAILPACK_RUNTIME_IMAGE
+}
+
 func (c *GenerateContext) DefaultRuntimeInput() plan.Input {
 	return c.DefaultRuntimeInputWithPackages([]string{})
 }
@@ -169,12 +188,20 @@ func (c *GenerateContext) DefaultRuntimeInputWithPackages(additionalAptPackages
 	aptPackages := append(c.Config.Deploy.AptPackages, additionalAptPackages...)
 
 	if len(aptPackages) == 0 {
-		return plan.NewImageInput(plan.RAILPACK_RUNTIME_IMAGE)
+		return plan.NewImageInput(c.RuntimeImage())
+	}
+
+	// The runtime step may have already been created with only the configured packages
+	if existingStep := c.GetStepByName(c.GetStepName("packages:runtime")); existingStep != nil {
+		if runtimeAptStep, ok := (*existingStep).(*AptStepBuilder); ok {
+			runtimeAptStep.Packages = aptPackages
+			return plan.NewStepInput(runtimeAptStep.Name())
+		}
 	}
 
 	runtimeAptStep := c.NewAptStepBuilder("runtime")
 	runtimeAptStep.Packages = aptPackages
-	runtimeAptStep.AddInput(plan.NewImageInput(plan.RAILPACK_RUNTIME_IMAGE))
+	runtimeAptStep.AddInput(plan.NewImageInput(c.RuntimeImage()))
 
 	return plan.NewStepInput(runtimeAptStep.Name())
 }
@@ -183,21 +210,40 @@ func (o *BuildStepOptions) NewAptInstallCommand(pkgs []string) plan.Command {
 	pkgs = utils.RemoveDuplicates(pkgs)
 	sort.Strings(pkgs)
 
-	return plan.NewExecCommand("sh -c 'apt-get update && apt-get install -y "+strings.Join(pkgs, " ")+"'", plan.ExecOptions{
+	return plan.NewInstallCommand("sh -c 'apt-get update && apt-get install -y "+strings.Join(pkgs, " ")+"'", plan.ExecOptions{
 		CustomName: "install apt packages: " + strings.Join(pkgs, " "),
 	})
 }
 
+// resolveConfigCache makes a relative cache directory from the config absolute.
+// Earlier versions of Railpack resolved them against the /app working directory, so configs that rely on it keep working
+func (c *GenerateContext) resolveConfigCache(name string, cache *plan.Cache) *plan.Cache {
+	if cache == nil || cache.Directory == "" || path.IsAbs(cache.Directory) {
+		return cache
+	}
+
+	resolved := *cache
+	resolved.Directory = path.Join("/app", cache.Directory)
+	c.Logger.LogWarn("cache %s has the relative directory %q, using %q instead", name, cache.Directory, resolved.Directory)
+
+	return &resolved
+}
+
 func (c *GenerateContext) applyConfig() {
-	miseStep := c.GetMiseStepBuilder()
-	for _, pkg := range slices.Sorted(maps.Keys(c.Config.Packages)) {
-		version := c.Config.Packages[pkg]
-		pkgRef := miseStep.Default(pkg, version)
-		miseStep.Version(pkgRef, version, "custom config")
+	// Only create the mise step if there are packages to install, otherwise nothing would use it
+	if len(c.Config.Packages) > 0 {
+		miseStep := c.GetMiseStepBuilder()
+		for _, pkg := range slices.Sorted(maps.Keys(c.Config.Packages)) {
+			version := c.Config.Packages[pkg]
+			pkgRef := miseStep.Default(pkg, version)
+			miseStep.Version(pkgRef, version, "custom config")
+		}
 	}
 
 	// Apply the cache config to the context
-	maps.Copy(c.Caches.Caches, c.Config.Caches)
+	for _, name := range slices.Sorted(maps.Keys(c.Config.Caches)) {
+		c.Caches.Caches[name] = c.resolveConfigCache(name, c.Config.Caches[name])
+	}
 	c.Secrets = plan.SpreadStrings(c.Config.Secrets, c.Secrets)
 
 	// Apply step config to the context
@@ -217,7 +263,7 @@ func (c *GenerateContext) applyConfig() {
 			// If no build step found, create a new one
 			// Run the build in the builder context and copy the /app contents to the final image
 			commandStepBuilder = c.NewCommandStep(name)
-			commandStepBuilder.AddInput(plan.NewStepInput(miseStep.Name()))
+			commandStepBuilder.AddInput(plan.NewStepInput(c.GetMiseStepBuilder().Name()))
 			c.Deploy.Inputs = append(c.Deploy.Inputs, plan.NewStepInput(commandStepBuilder.Name(), plan.InputOptions{
 				Include: []string{"."},
 			}))
@@ -239,9 +285,28 @@ func (c *GenerateContext) applyConfig() {
 			c.Deploy.StartCmd = c.Config.Deploy.StartCmd
 		}
 
+		if c.Config.Deploy.ReleaseCmd != "" {
+			c.Deploy.ReleaseCmd = c.Config.Deploy.ReleaseCmd
+		}
+
+		if c.Config.Deploy.Healthcheck != nil {
+			c.Deploy.Healthcheck = c.Config.Deploy.Healthcheck
+		}
+
+		if c.Config.Deploy.StopSignal != "" {
+			c.Deploy.StopSignal = c.Config.Deploy.StopSignal
+		}
+
+		if c.Config.Deploy.User != "" {
+			c.Deploy.User = c.Config.Deploy.User
+		}
+
 		c.Deploy.Inputs = plan.Spread(c.Config.Deploy.Inputs, c.Deploy.Inputs)
 		c.Deploy.Paths = plan.SpreadStrings(c.Config.Deploy.Paths, c.Deploy.Paths)
+		c.Deploy.Ports = plan.SpreadStrings(c.Config.Deploy.Ports, c.Deploy.Ports)
 		maps.Copy(c.Deploy.Variables, c.Config.Deploy.Variables)
+		maps.Copy(c.Deploy.Processes, c.Config.Deploy.Processes)
+		maps.Copy(c.Deploy.Labels, c.Config.Deploy.Labels)
 	}
 
 }
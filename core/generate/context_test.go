@@ -9,6 +9,7 @@ import (
 	"github.com/railwayapp/railpack/core/config"
 	"github.com/railwayapp/railpack/core/logger"
 	"github.com/railwayapp/railpack/core/plan"
+	"github.com/railwayapp/railpack/core/resolver"
 	"github.com/stretchr/testify/require"
 )
 
@@ -104,3 +105,98 @@ func TestGenerateContext(t *testing.T) {
 
 	snaps.MatchJSON(t, serializedPlan)
 }
+
+func TestGenerateContextWithoutPackages(t *testing.T) {
+	ctx := CreateTestContext(t, "../../examples/node-npm")
+	ctx.Config.Deploy.StartCmd = "echo hello"
+
+	buildPlan, _, err := ctx.Generate()
+	require.NoError(t, err)
+
+	// Nothing uses the mise step when the config has no packages
+	require.Empty(t, buildPlan.Steps)
+}
+
+func TestDefaultRuntimeInputWithPackages(t *testing.T) {
+	ctx := CreateTestContext(t, "../../examples/node-npm")
+	ctx.Config.Deploy.AptPackages = []string{"neofetch"}
+
+	require.Equal(t, plan.NewStepInput("packages:runtime"), ctx.DefaultRuntimeInput())
+	require.Equal(t, plan.NewStepInput("packages:runtime"), ctx.DefaultRuntimeInputWithPackages([]string{"git"}))
+
+	buildPlan, _, err := ctx.Generate()
+	require.NoError(t, err)
+
+	// The runtime step is reused instead of being added again
+	require.Len(t, buildPlan.Steps, 1)
+	require.Equal(t, "packages:runtime", buildPlan.Steps[0].Name)
+	require.Equal(t, []plan.Command{
+		plan.NewInstallCommand("sh -c 'apt-get update && apt-get install -y git neofetch'", plan.ExecOptions{
+			CustomName: "install apt packages: git neofetch",
+		}),
+	}, buildPlan.Steps[0].Commands)
+}
+
+func TestGenerateContextBaseImages(t *testing.T) {
+	userApp, err := app.NewApp("../../examples/node-npm")
+	require.NoError(t, err)
+
+	cfg := config.EmptyConfig()
+	cfg.Deploy.AptPackages = []string{"curl"}
+	cfg.BaseImages = &config.BaseImagesConfig{
+		Builder: "registry.example.com/railpack-builder@sha256:abc",
+		Runtime: "registry.example.com/railpack-runtime:v1",
+	}
+
+	ctx, err := NewGenerateContext(userApp, app.NewEnvironment(nil), cfg, logger.NewLogger())
+	require.NoError(t, err)
+
+	require.Equal(t, cfg.BaseImages.Builder, ctx.BuilderImage())
+	require.Equal(t, cfg.BaseImages.Runtime, ctx.RuntimeImage())
+
+	options := &BuildStepOptions{
+		ResolvedPackages: map[string]*resolver.ResolvedPackage{},
+		Caches:           ctx.Caches,
+	}
+
+	miseStep, err := ctx.GetMiseStepBuilder().Build(options)
+	require.NoError(t, err)
+	require.Equal(t, []plan.Input{plan.NewImageInput(cfg.BaseImages.Builder)}, miseStep.Inputs)
+
+	version := "1.0.0"
+	options.ResolvedPackages["caddy"] = &resolver.ResolvedPackage{Name: "caddy", ResolvedVersion: &version}
+	binStepBuilder := ctx.NewInstallBinStepBuilder("packages:caddy")
+	binStepBuilder.Package = resolver.PackageRef{Name: "caddy"}
+	binStep, err := binStepBuilder.Build(options)
+	require.NoError(t, err)
+	require.Equal(t, []plan.Input{plan.NewImageInput(cfg.BaseImages.Builder)}, binStep.Inputs)
+
+	// The runtime apt packages are installed on top of the runtime image
+	runtimeStep := ctx.GetStepByName("packages:runtime")
+	require.NotNil(t, runtimeStep)
+	aptStep, err := (*runtimeStep).Build(options)
+	require.NoError(t, err)
+	require.Equal(t, []plan.Input{plan.NewImageInput(cfg.BaseImages.Runtime)}, aptStep.Inputs)
+
+	// Without overrides the default images are used
+	ctx = CreateTestContext(t, "../../examples/node-npm")
+	require.Equal(t, plan.RAILPACK_BUILDER_IMAGE, ctx.BuilderImage())
+	require.Equal(t, []plan.Input{plan.NewImageInput(plan.RAILPACK_RUNTIME_IMAGE)}, ctx.Deploy.Inputs)
+}
+
+func TestGenerateContextRelativeCacheDirectory(t *testing.T) {
+	ctx := CreateTestContext(t, "../../examples/node-npm")
+	ctx.Config.Caches = map[string]*plan.Cache{
+		"vite": plan.NewCache("node_modules/.vite"),
+		"npm":  plan.NewCache("/root/.npm"),
+	}
+	ctx.Config.Deploy.StartCmd = "echo hello"
+
+	buildPlan, _, err := ctx.Generate()
+	require.NoError(t, err)
+
+	// Relative directories are resolved against /app and the config is left unchanged
+	require.Equal(t, "/app/node_modules/.vite", buildPlan.Caches["vite"].Directory)
+	require.Equal(t, "/root/.npm", buildPlan.Caches["npm"].Directory)
+	require.Equal(t, "node_modules/.vite", ctx.Config.Caches["vite"].Directory)
+}
@@ -5,26 +5,45 @@ import "github.com/railwayapp/railpack/core/plan"
 type DeployBuilder struct {
 	Inputs      []plan.Input
 	StartCmd    string
+	ReleaseCmd  string
 	Variables   map[string]string
 	Paths       []string
+	Processes   map[string]string
 	AptPackages []string
+	Ports       []string
+	Healthcheck *plan.Healthcheck
+	StopSignal  string
+	User        string
+	Labels      map[string]string
 }
 
 func NewDeployBuilder() *DeployBuilder {
 	return &DeployBuilder{
 		Inputs:      []plan.Input{},
 		StartCmd:    "",
+		ReleaseCmd:  "",
 		Variables:   map[string]string{},
 		Paths:       []string{},
+		Processes:   map[string]string{},
 		AptPackages: []string{},
+		Ports:       []string{},
+		User:        plan.RAILPACK_USER,
+		Labels:      map[string]string{},
 	}
 }
 
 func (b *DeployBuilder) Build() plan.Deploy {
 	return plan.Deploy{
-		Inputs:    b.Inputs,
-		StartCmd:  b.StartCmd,
-		Variables: b.Variables,
-		Paths:     b.Paths,
+		Inputs:      b.Inputs,
+		StartCmd:    b.StartCmd,
+		ReleaseCmd:  b.ReleaseCmd,
+		Variables:   b.Variables,
+		Paths:       b.Paths,
+		Processes:   b.Processes,
+		Ports:       b.Ports,
+		Healthcheck: b.Healthcheck,
+		StopSignal:  b.StopSignal,
+		User:        b.User,
+		Labels:      b.Labels,
 	}
 }
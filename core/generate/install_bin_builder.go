@@ -16,13 +16,15 @@ type InstallBinStepBuilder struct {
 	Resolver              *resolver.Resolver
 	SupportingAptPackages []string
 	Package               resolver.PackageRef
+	BuilderImage          string
 }
 
 func (c *GenerateContext) NewInstallBinStepBuilder(name string) *InstallBinStepBuilder {
 	step := &InstallBinStepBuilder{
-		DisplayName: c.GetStepName(name),
-		Resolver:    c.Resolver,
-		Package:     resolver.PackageRef{},
+		DisplayName:  c.GetStepName(name),
+		Resolver:     c.Resolver,
+		Package:      resolver.PackageRef{},
+		BuilderImage: c.BuilderImage(),
 	}
 
 	c.Steps = append(c.Steps, step)
@@ -56,13 +58,13 @@ func (b *InstallBinStepBuilder) Build(options *BuildStepOptions) (*plan.Step, er
 	step := plan.NewStep(b.DisplayName)
 
 	step.Inputs = []plan.Input{
-		plan.NewImageInput(plan.RAILPACK_BUILDER_IMAGE),
+		plan.NewImageInput(b.BuilderImage),
 	}
 
 	binPath := b.getBinPath()
 
 	step.AddCommands([]plan.Command{
-		plan.NewExecCommand(fmt.Sprintf("mise install-into %s@%s %s", b.Package.Name, *packageVersion, binPath)),
+		plan.NewInstallCommand(fmt.Sprintf("mise install-into %s@%s %s", b.Package.Name, *packageVersion, binPath)),
 		plan.NewPathCommand(binPath),
 		plan.NewPathCommand(fmt.Sprintf("%s/bin", binPath)),
 	})
@@ -0,0 +1,215 @@
+package generate
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/railpack/core/logger"
+	"github.com/railwayapp/railpack/core/plan"
+)
+
+// Matches ${NAME} references and $${NAME} escapes. Shell syntax such as $NAME or ${NAME:-default} is not matched
+var variableReferenceRegex = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// variableInterpolator expands ${NAME} references to other plan variables when the plan is generated.
+//
+// Build time values (step variables, variable commands, deploy variables, and deploy paths) can reference the variables
+// of the same step and the variables inherited from its inputs. References that can't be resolved are left as is with a warning.
+// The start, release, and process commands are run by a shell in the container, so references to anything but a plan variable
+// are left for it to expand at runtime. References can be escaped with $${NAME}
+type variableInterpolator struct {
+	plan   *plan.BuildPlan
+	logger *logger.Logger
+
+	steps      map[string]*plan.Step
+	outputEnvs map[string]map[string]string
+	visiting   map[string]bool
+}
+
+// interpolateVariables expands the variable references of the plan in place
+func interpolateVariables(buildPlan *plan.BuildPlan, logger *logger.Logger) {
+	i := &variableInterpolator{
+		plan:       buildPlan,
+		logger:     logger,
+		steps:      make(map[string]*plan.Step, len(buildPlan.Steps)),
+		outputEnvs: make(map[string]map[string]string, len(buildPlan.Steps)),
+		visiting:   make(map[string]bool),
+	}
+
+	for idx := range buildPlan.Steps {
+		i.steps[buildPlan.Steps[idx].Name] = &buildPlan.Steps[idx]
+	}
+
+	for idx := range buildPlan.Steps {
+		i.stepOutputEnv(buildPlan.Steps[idx].Name)
+	}
+
+	i.interpolateDeploy()
+}
+
+// stepOutputEnv interpolates the variables of a step and returns the variables it passes on to the steps that use it
+func (i *variableInterpolator) stepOutputEnv(name string) map[string]string {
+	if env, ok := i.outputEnvs[name]; ok {
+		return env
+	}
+
+	step, ok := i.steps[name]
+
+	// Unknown steps and cycles are reported when the plan is validated
+	if !ok || i.visiting[name] {
+		return map[string]string{}
+	}
+	i.visiting[name] = true
+	defer delete(i.visiting, name)
+
+	inherited := i.inputsEnv(step.Inputs)
+	context := "step " + name
+
+	step.Variables = i.interpolateMap(step.Variables, inherited, context)
+
+	env := maps.Clone(inherited)
+	maps.Copy(env, step.Variables)
+	output := maps.Clone(env)
+
+	for idx, cmd := range step.Commands {
+		variableCmd, ok := cmd.(plan.VariableCommand)
+		if !ok {
+			continue
+		}
+
+		variableCmd.Value = i.interpolate(variableCmd.Value, env, context+" variable "+variableCmd.Name, true)
+		step.Commands[idx] = variableCmd
+
+		env[variableCmd.Name] = variableCmd.Value
+		if variableCmd.Global {
+			output[variableCmd.Name] = variableCmd.Value
+		}
+	}
+
+	i.outputEnvs[name] = output
+	return output
+}
+
+func (i *variableInterpolator) interpolateDeploy() {
+	deploy := &i.plan.Deploy
+	inherited := i.inputsEnv(deploy.Inputs)
+
+	deploy.Variables = i.interpolateMap(deploy.Variables, inherited, "deploy")
+
+	env := maps.Clone(inherited)
+	maps.Copy(env, deploy.Variables)
+
+	for idx, path := range deploy.Paths {
+		deploy.Paths[idx] = i.interpolate(path, env, "deploy path", true)
+	}
+
+	deploy.StartCmd = i.interpolate(deploy.StartCmd, env, "start command", false)
+	deploy.ReleaseCmd = i.interpolate(deploy.ReleaseCmd, env, "release command", false)
+
+	for name, cmd := range deploy.Processes {
+		deploy.Processes[name] = i.interpolate(cmd, env, "process "+name, false)
+	}
+}
+
+// inputsEnv merges the variables passed on by the input steps
+func (i *variableInterpolator) inputsEnv(inputs []plan.Input) map[string]string {
+	env := map[string]string{}
+	for _, input := range inputs {
+		if input.Step != "" {
+			maps.Copy(env, i.stepOutputEnv(input.Step))
+		}
+	}
+	return env
+}
+
+// interpolateMap expands the references of a set of variables that can reference each other.
+// A variable that references itself (e.g. NODE_OPTIONS=${NODE_OPTIONS} --inspect) gets the inherited value.
+// Variables that reference each other in a cycle are left unexpanded with a warning
+func (i *variableInterpolator) interpolateMap(variables map[string]string, inherited map[string]string, context string) map[string]string {
+	if len(variables) == 0 {
+		return variables
+	}
+
+	resolved := make(map[string]string, len(variables))
+	resolving := []string{}
+	inCycle := map[string]bool{}
+
+	var resolve func(name string) (string, bool)
+	resolve = func(name string) (string, bool) {
+		if value, ok := resolved[name]; ok {
+			return value, true
+		}
+
+		raw, ok := variables[name]
+		idx := slices.Index(resolving, name)
+		if !ok || (idx != -1 && idx == len(resolving)-1) {
+			value, ok := inherited[name]
+			return value, ok
+		}
+
+		if idx != -1 {
+			cycle := resolving[idx:]
+			for _, cycleName := range cycle {
+				inCycle[cycleName] = true
+			}
+			i.logger.LogWarn("%s variables %s reference each other", context, joinNames(cycle))
+			return raw, true
+		}
+
+		resolving = append(resolving, name)
+		value := i.expand(raw, resolve, context+" variable "+name, true)
+		resolving = resolving[:len(resolving)-1]
+
+		if inCycle[name] {
+			value = raw
+		}
+
+		resolved[name] = value
+		return value, true
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(variables)) {
+		resolve(name)
+	}
+
+	return resolved
+}
+
+// joinNames joins names as a list, e.g. "A, B and C"
+func joinNames(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+func (i *variableInterpolator) interpolate(value string, env map[string]string, context string, warnUndefined bool) string {
+	return i.expand(value, func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}, context, warnUndefined)
+}
+
+func (i *variableInterpolator) expand(value string, lookup func(name string) (string, bool), context string, warnUndefined bool) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+
+	return variableReferenceRegex.ReplaceAllStringFunc(value, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+
+		name := match[2 : len(match)-1]
+		if resolved, ok := lookup(name); ok {
+			return resolved
+		}
+
+		if warnUndefined {
+			i.logger.LogWarn("%s references undefined variable %s", context, name)
+		}
+		return match
+	})
+}
@@ -0,0 +1,120 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/railwayapp/railpack/core/logger"
+	"github.com/railwayapp/railpack/core/plan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolateVariables(t *testing.T) {
+	newPlan := func() *plan.BuildPlan {
+		buildPlan := plan.NewBuildPlan()
+
+		mise := plan.NewStep("packages:mise")
+		mise.Variables = map[string]string{
+			"MISE_DATA_DIR": "/mise",
+			"MISE_SHIMS":    "${MISE_DATA_DIR}/shims",
+		}
+		mise.Commands = []plan.Command{
+			plan.NewVariableCommand("JAVA_HOME", "${MISE_DATA_DIR}/installs/java/21", plan.VariableOptions{Global: true}),
+			plan.NewVariableCommand("JAVA_BIN", "${JAVA_HOME}/bin"),
+		}
+		buildPlan.AddStep(*mise)
+
+		build := plan.NewStep("build")
+		build.Inputs = []plan.Input{plan.NewStepInput("packages:mise")}
+		build.Variables = map[string]string{
+			"NODE_OPTIONS": "${NODE_OPTIONS} --max-old-space-size=4096",
+			"GRADLE_HOME":  "${JAVA_HOME}/gradle",
+			"LITERAL":      "$${MISE_DATA_DIR} $HOME ${HOME:-/root}",
+		}
+		buildPlan.AddStep(*build)
+
+		buildPlan.Deploy = plan.Deploy{
+			Inputs:    []plan.Input{plan.NewStepInput("build")},
+			Variables: map[string]string{"APP_HOME": "/app", "CONFIG": "${APP_HOME}/config"},
+			Paths:     []string{"${APP_HOME}/bin", "${JAVA_HOME}/bin"},
+			StartCmd:  "${APP_HOME}/bin/server --port ${PORT} --config ${CONFIG}",
+		}
+
+		return buildPlan
+	}
+
+	t.Run("resolves references", func(t *testing.T) {
+		log := logger.NewLogger()
+		buildPlan := newPlan()
+		interpolateVariables(buildPlan, log)
+
+		require.Equal(t, map[string]string{
+			"MISE_DATA_DIR": "/mise",
+			"MISE_SHIMS":    "/mise/shims",
+		}, buildPlan.Steps[0].Variables)
+		require.Equal(t, []plan.Command{
+			plan.NewVariableCommand("JAVA_HOME", "/mise/installs/java/21", plan.VariableOptions{Global: true}),
+			plan.NewVariableCommand("JAVA_BIN", "/mise/installs/java/21/bin"),
+		}, buildPlan.Steps[0].Commands)
+
+		require.Equal(t, map[string]string{
+			"NODE_OPTIONS": "${NODE_OPTIONS} --max-old-space-size=4096",
+			"GRADLE_HOME":  "/mise/installs/java/21/gradle",
+			"LITERAL":      "${MISE_DATA_DIR} $HOME ${HOME:-/root}",
+		}, buildPlan.Steps[1].Variables)
+
+		require.Equal(t, "/app/config", buildPlan.Deploy.Variables["CONFIG"])
+		require.Equal(t, []string{"/app/bin", "/mise/installs/java/21/bin"}, buildPlan.Deploy.Paths)
+		require.Equal(t, "/app/bin/server --port ${PORT} --config /app/config", buildPlan.Deploy.StartCmd)
+
+		// NODE_OPTIONS is not inherited from any step, and ${PORT} is left for the start command to expand at runtime
+		require.Equal(t, []logger.Msg{
+			{Level: logger.Warn, Msg: "step build variable NODE_OPTIONS references undefined variable NODE_OPTIONS"},
+		}, log.Logs)
+	})
+
+	t.Run("self references use the inherited value", func(t *testing.T) {
+		log := logger.NewLogger()
+		buildPlan := newPlan()
+		buildPlan.Steps[0].Variables["NODE_OPTIONS"] = "--enable-source-maps"
+		interpolateVariables(buildPlan, log)
+
+		require.Equal(t, "--enable-source-maps --max-old-space-size=4096", buildPlan.Steps[1].Variables["NODE_OPTIONS"])
+		require.Empty(t, log.Logs)
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		log := logger.NewLogger()
+		buildPlan := plan.NewBuildPlan()
+		buildPlan.Deploy.Variables = map[string]string{"A": "${B}", "B": "${A}"}
+		interpolateVariables(buildPlan, log)
+
+		require.Equal(t, map[string]string{"A": "${B}", "B": "${A}"}, buildPlan.Deploy.Variables)
+		require.Equal(t, []logger.Msg{
+			{Level: logger.Warn, Msg: "deploy variables A and B reference each other"},
+		}, log.Logs)
+	})
+
+	t.Run("longer cycles", func(t *testing.T) {
+		log := logger.NewLogger()
+		buildPlan := plan.NewBuildPlan()
+		buildPlan.Deploy.Variables = map[string]string{"A": "${B}/a", "B": "${C}/b", "C": "${A}/c", "D": "/d"}
+		interpolateVariables(buildPlan, log)
+
+		require.Equal(t, map[string]string{"A": "${B}/a", "B": "${C}/b", "C": "${A}/c", "D": "/d"}, buildPlan.Deploy.Variables)
+		require.Equal(t, []logger.Msg{
+			{Level: logger.Warn, Msg: "deploy variables A, B and C reference each other"},
+		}, log.Logs)
+	})
+
+	t.Run("release and process commands", func(t *testing.T) {
+		log := logger.NewLogger()
+		buildPlan := newPlan()
+		buildPlan.Deploy.ReleaseCmd = "${APP_HOME}/bin/migrate --url ${DATABASE_URL}"
+		buildPlan.Deploy.Processes = map[string]string{"worker": "${APP_HOME}/bin/worker --config ${CONFIG} --queue $${QUEUE}"}
+		interpolateVariables(buildPlan, log)
+
+		require.Equal(t, "/app/bin/migrate --url ${DATABASE_URL}", buildPlan.Deploy.ReleaseCmd)
+		require.Equal(t, map[string]string{"worker": "/app/bin/worker --config /app/config --queue ${QUEUE}"}, buildPlan.Deploy.Processes)
+		require.Len(t, log.Logs, 1)
+	})
+}
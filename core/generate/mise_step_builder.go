@@ -25,6 +25,7 @@ type MiseStepBuilder struct {
 	Assets                map[string]string
 	Inputs                []plan.Input
 	Variables             map[string]string
+	BuilderImage          string
 	app                   *a.App
 	env                   *a.Environment
 }
@@ -40,6 +41,7 @@ func (c *GenerateContext) NewMiseStepBuilder(displayName string) *MiseStepBuilde
 		Assets:                map[string]string{},
 		Inputs:                []plan.Input{},
 		Variables:             map[string]string{},
+		BuilderImage:          c.BuilderImage(),
 		app:                   c.App,
 		env:                   c.Env,
 	}
@@ -94,7 +96,7 @@ func (b *MiseStepBuilder) Build(options *BuildStepOptions) (*plan.Step, error) {
 	step := plan.NewStep(b.DisplayName)
 
 	step.Inputs = []plan.Input{
-		plan.NewImageInput(plan.RAILPACK_BUILDER_IMAGE),
+		plan.NewImageInput(b.BuilderImage),
 	}
 
 	// Setup apt commands
@@ -161,7 +163,7 @@ func (b *MiseStepBuilder) Build(options *BuildStepOptions) (*plan.Step, error) {
 		plan.NewFileCommand("/etc/mise/config.toml", "mise.toml", plan.FileOptions{
 			CustomName: "create mise config",
 		}),
-		plan.NewExecCommand("sh -c 'mise trust -a && mise install'", plan.ExecOptions{
+		plan.NewInstallCommand("sh -c 'mise trust -a && mise install'", plan.ExecOptions{
 			CustomName: "install mise packages: " + strings.Join(pkgNames, ", "),
 		}),
 	})
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/railpack/core/app"
+	"github.com/railwayapp/railpack/core/generate"
+	"github.com/railwayapp/railpack/core/plan"
+)
+
+const (
+	labelEnvPrefix = "RAILPACK_LABEL_"
+	labelsEnvName  = "RAILPACK_LABELS"
+)
+
+// setDefaultLabels adds the labels railpack includes on every image.
+// Labels from the config are applied afterwards and take precedence
+func setDefaultLabels(ctx *generate.GenerateContext, options *GenerateBuildPlanOptions, providerName string) {
+	if revision := getSourceRevision(ctx.App); revision != "" {
+		ctx.Deploy.Labels[plan.LabelRevision] = revision
+	}
+
+	if options.RailpackVersion != "" {
+		ctx.Deploy.Labels[plan.LabelRailpackVersion] = options.RailpackVersion
+	}
+
+	if providerName != "" {
+		ctx.Deploy.Labels[plan.LabelRailpackProvider] = providerName
+	}
+}
+
+// getLabelsFromEnvironment returns the labels set with the RAILPACK_LABELS and RAILPACK_LABEL_* variables.
+// RAILPACK_LABELS is a comma separated list of key=value pairs whose keys are used as is (e.g. com.example.build_id=123).
+// For RAILPACK_LABEL_* variables the label key is the lowercased suffix with underscores replaced by dots and
+// double underscores replaced by a single underscore (e.g. RAILPACK_LABEL_COM_EXAMPLE_BUILD__ID -> com.example.build_id).
+// They take precedence over RAILPACK_LABELS
+func getLabelsFromEnvironment(env *app.Environment) map[string]string {
+	labels := map[string]string{}
+
+	for _, pair := range strings.Split(env.Variables[labelsEnvName], ",") {
+		key, value, found := strings.Cut(pair, "=")
+		if key = strings.TrimSpace(key); found && key != "" {
+			labels[key] = strings.TrimSpace(value)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(env.Variables)) {
+		suffix, found := strings.CutPrefix(name, labelEnvPrefix)
+		if !found || suffix == "" {
+			continue
+		}
+
+		labels[getLabelKey(suffix)] = strings.TrimSpace(env.Variables[name])
+	}
+
+	return labels
+}
+
+// getLabelKey converts the suffix of a RAILPACK_LABEL_* variable to a label key
+func getLabelKey(suffix string) string {
+	parts := strings.Split(strings.ToLower(suffix), "__")
+	for i, part := range parts {
+		parts[i] = strings.ReplaceAll(part, "_", ".")
+	}
+	return strings.Join(parts, "_")
+}
+
+// getSourceRevision returns the commit the app's git checkout is at, if there is one
+func getSourceRevision(a *app.App) string {
+	head, err := a.ReadFile(".git/HEAD")
+	if err != nil {
+		return ""
+	}
+
+	head = strings.TrimSpace(head)
+	ref, isRef := strings.CutPrefix(head, "ref: ")
+	if !isRef {
+		return head
+	}
+
+	if sha, err := a.ReadFile(".git/" + ref); err == nil {
+		return strings.TrimSpace(sha)
+	}
+
+	// The ref may have been packed
+	packedRefs, err := a.ReadFile(".git/packed-refs")
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(packedRefs, "\n") {
+		if sha, name, ok := strings.Cut(strings.TrimSpace(line), " "); ok && name == ref {
+			return sha
+		}
+	}
+
+	return ""
+}
@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Command interface {
@@ -12,14 +15,47 @@ type Command interface {
 	Spreadable
 }
 
+// Commands that download dependencies mostly fail on transient network errors, so they are retried a couple of times
+const (
+	DEFAULT_INSTALL_RETRIES     = 2
+	DEFAULT_INSTALL_RETRY_DELAY = "5s"
+)
+
+var variableNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// The network modes an exec command can run with
+const (
+	NetworkModeDefault = "default"
+	NetworkModeNone    = "none"
+	NetworkModeHost    = "host"
+)
+
 type ExecOptions struct {
 	CustomName string
+	Retries    int
+	RetryDelay string
+	Timeout    string
+	Dir        string
+	User       string
+	Network    string
 }
 
 // ExecCommand represents a shell command to be executed during the build
 type ExecCommand struct {
 	Cmd        string `json:"cmd" jsonschema:"description=The shell command to execute (e.g. 'go build' or 'npm install')"`
 	CustomName string `json:"customName,omitempty" jsonschema:"description=Optional custom name to display for this command in build output"`
+	Retries    int    `json:"retries,omitempty" jsonschema:"description=The number of times to retry the command if it fails"`
+	RetryDelay string `json:"retryDelay,omitempty" jsonschema:"description=The time to wait before retrying the command (e.g. 5s)"`
+	Timeout    string `json:"timeout,omitempty" jsonschema:"description=The maximum time the command can run before it is stopped (e.g. 10m). Each retry gets the full timeout"`
+	Dir        string `json:"dir,omitempty" jsonschema:"description=The directory to run the command in. Relative paths are relative to /app"`
+	User       string `json:"user,omitempty" jsonschema:"description=The user to run the command as (a user name or uid:gid). Defaults to the user of the step image"`
+	Network    string `json:"network,omitempty" jsonschema:"enum=default,enum=none,enum=host,description=The network mode of the command: default or none or host"`
+}
+
+// ExecDurations are the parsed durations of an exec command
+type ExecDurations struct {
+	RetryDelay time.Duration
+	Timeout    time.Duration
 }
 
 // PathCommand represents adding a directory to the global PATH environment variable
@@ -34,6 +70,17 @@ type CopyCommand struct {
 	Dest  string `json:"dest" jsonschema:"description=Destination path to copy to. Will be created if it doesn't exist"`
 }
 
+type VariableOptions struct {
+	Global bool
+}
+
+// VariableCommand represents setting an environment variable for the rest of the step
+type VariableCommand struct {
+	Name   string `json:"name" jsonschema:"description=Name of the environment variable to set"`
+	Value  string `json:"value" jsonschema:"description=Value of the environment variable"`
+	Global bool   `json:"global,omitempty" jsonschema:"description=Also make the variable available to the steps that use this step as an input and to the final image"`
+}
+
 type FileOptions struct {
 	Mode       os.FileMode
 	CustomName string
@@ -47,19 +94,44 @@ type FileCommand struct {
 	CustomName string      `json:"customName,omitempty" jsonschema:"description=Optional custom name to display for this file operation"`
 }
 
-func (e ExecCommand) CommandType() string { return "exec" }
-func (g PathCommand) CommandType() string { return "globalPath" }
-func (c CopyCommand) CommandType() string { return "copy" }
-func (f FileCommand) CommandType() string { return "file" }
+func (e ExecCommand) CommandType() string     { return "exec" }
+func (g PathCommand) CommandType() string     { return "globalPath" }
+func (c CopyCommand) CommandType() string     { return "copy" }
+func (f FileCommand) CommandType() string     { return "file" }
+func (v VariableCommand) CommandType() string { return "variable" }
 
 func NewExecCommand(cmd string, options ...ExecOptions) Command {
 	exec := ExecCommand{Cmd: cmd}
 	if len(options) > 0 {
 		exec.CustomName = options[0].CustomName
+		exec.Retries = options[0].Retries
+		exec.RetryDelay = options[0].RetryDelay
+		exec.Timeout = options[0].Timeout
+		exec.Dir = options[0].Dir
+		exec.User = options[0].User
+		exec.Network = options[0].Network
 	}
 	return exec
 }
 
+// NewInstallCommand creates a command that downloads dependencies (e.g. npm ci).
+// It is retried on failure unless the options set the retries
+func NewInstallCommand(cmd string, options ...ExecOptions) Command {
+	opts := ExecOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	if opts.Retries == 0 {
+		opts.Retries = DEFAULT_INSTALL_RETRIES
+	}
+	if opts.RetryDelay == "" {
+		opts.RetryDelay = DEFAULT_INSTALL_RETRY_DELAY
+	}
+
+	return NewExecCommand(cmd, opts)
+}
+
 func ShellCommandString(cmd string) string {
 	return "sh -c '" + cmd + "'"
 }
@@ -90,6 +162,14 @@ func NewCopyCommand(src string, dst ...string) Command {
 	return copyCmd
 }
 
+func NewVariableCommand(name, value string, options ...VariableOptions) Command {
+	variableCmd := VariableCommand{Name: name, Value: value}
+	if len(options) > 0 {
+		variableCmd.Global = options[0].Global
+	}
+	return variableCmd
+}
+
 func NewFileCommand(path, name string, options ...FileOptions) Command {
 	fileCmd := FileCommand{Path: path, Name: name}
 	if len(options) > 0 {
@@ -125,6 +205,14 @@ func UnmarshalJsonCommand(data []byte) (Command, error) {
 		return cmd, nil
 	}
 
+	if _, ok := rawMap["value"]; ok {
+		var variable VariableCommand
+		if err := json.Unmarshal(data, &variable); err != nil {
+			return nil, err
+		}
+		return variable, nil
+	}
+
 	if _, ok := rawMap["path"]; ok {
 		if _, ok := rawMap["name"]; ok {
 			var file FileCommand
@@ -151,36 +239,47 @@ func UnmarshalJsonCommand(data []byte) (Command, error) {
 	return nil, fmt.Errorf("unknown command type: %v", rawMap)
 }
 
+// UnmarshalStringCommand parses a command in the string format.
+// Commands are written as TYPE[options]#Custom Name:payload, where the options and custom name are optional
+// (e.g. RUN[retries=3,timeout=10m,dir=web]#Install:npm ci). Strings without a known type are run as exec commands
 func UnmarshalStringCommand(data []byte) (Command, error) {
 	str := string(data)
 
-	// If no prefix, treat as exec command
-	if !strings.Contains(str, ":") {
-		cmdToRun := strings.Trim(str, "\"")
-		return NewExecShellCommand(cmdToRun, ExecOptions{CustomName: cmdToRun}), nil
+	// Strings from a config file are still JSON encoded
+	var decoded string
+	if err := json.Unmarshal(data, &decoded); err == nil {
+		str = decoded
 	}
 
-	parts := strings.SplitN(str, ":", 2)
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid command format: %s", str)
+	cmdType, options, customName, payload, ok := splitStringCommand(str)
+	if !ok {
+		return NewExecShellCommand(str, ExecOptions{CustomName: str}), nil
 	}
 
-	prefix := parts[0]
-	payload := parts[1]
-
-	// Split prefix into command type and custom name
-	prefixParts := strings.SplitN(prefix, "#", 2)
-	cmdType := prefixParts[0]
-	customName := ""
-	if len(prefixParts) > 1 {
-		customName = prefixParts[1]
+	if options != "" && cmdType != "RUN" && cmdType != "ENV" {
+		return nil, fmt.Errorf("invalid command `%s`: options are only supported for RUN and ENV commands", str)
 	}
 
 	switch cmdType {
 	case "RUN":
-		return NewExecShellCommand(payload, ExecOptions{CustomName: customName}), nil
+		execOptions, err := parseExecOptions(options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid command `%s`: %w", str, err)
+		}
+		execOptions.CustomName = customName
+		return NewExecShellCommand(payload, execOptions), nil
 	case "PATH":
 		return NewPathCommand(payload), nil
+	case "ENV":
+		name, value, found := strings.Cut(payload, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid ENV format: %s", payload)
+		}
+		variableOptions, err := parseVariableOptions(options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid command `%s`: %w", str, err)
+		}
+		return NewVariableCommand(strings.TrimSpace(name), value, variableOptions), nil
 	case "COPY":
 		copyParts := strings.Fields(payload)
 		if len(copyParts) != 2 {
@@ -196,11 +295,162 @@ func UnmarshalStringCommand(data []byte) (Command, error) {
 	}
 
 	// fallback to exec command type
-	cmdToRun := strings.Trim(str, "\"")
 	if customName == "" {
-		customName = cmdToRun
+		customName = str
+	}
+	return NewExecShellCommand(str, ExecOptions{CustomName: customName}), nil
+}
+
+// splitStringCommand splits a string command into its type, options, custom name, and payload.
+// The options are wrapped in brackets and can contain colons, so the prefix is scanned rather than split
+func splitStringCommand(str string) (cmdType, options, customName, payload string, ok bool) {
+	end := strings.IndexFunc(str, func(r rune) bool { return r < 'A' || r > 'Z' })
+	if end <= 0 {
+		return "", "", "", "", false
+	}
+	cmdType, rest := str[:end], str[end:]
+
+	if strings.HasPrefix(rest, "[") {
+		closing := strings.Index(rest, "]")
+		if closing == -1 {
+			return "", "", "", "", false
+		}
+		options, rest = rest[1:closing], rest[closing+1:]
+	}
+
+	if strings.HasPrefix(rest, "#") {
+		name, remaining, found := strings.Cut(rest[1:], ":")
+		if !found {
+			return "", "", "", "", false
+		}
+		return cmdType, options, name, remaining, true
+	}
+
+	payload, found := strings.CutPrefix(rest, ":")
+	return cmdType, options, "", payload, found
+}
+
+// parseExecOptions parses the comma separated key=value options of a RUN string command
+func parseExecOptions(options string) (ExecOptions, error) {
+	execOptions := ExecOptions{}
+	if strings.TrimSpace(options) == "" {
+		return execOptions, nil
 	}
-	return NewExecShellCommand(cmdToRun, ExecOptions{CustomName: customName}), nil
+
+	for _, option := range strings.Split(options, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(option), "=")
+		if !found {
+			return execOptions, fmt.Errorf("option `%s` must be in the format key=value", option)
+		}
+
+		switch key {
+		case "retries":
+			retries, err := strconv.Atoi(value)
+			if err != nil {
+				return execOptions, fmt.Errorf("retries must be a number, got `%s`", value)
+			}
+			execOptions.Retries = retries
+		case "retryDelay":
+			execOptions.RetryDelay = value
+		case "timeout":
+			execOptions.Timeout = value
+		case "dir":
+			execOptions.Dir = value
+		case "user":
+			execOptions.User = value
+		case "network":
+			execOptions.Network = value
+		default:
+			return execOptions, fmt.Errorf("unknown option `%s`", key)
+		}
+	}
+
+	return execOptions, nil
+}
+
+// parseVariableOptions parses the options of an ENV string command. The only option is global
+func parseVariableOptions(options string) (VariableOptions, error) {
+	variableOptions := VariableOptions{}
+	if strings.TrimSpace(options) == "" {
+		return variableOptions, nil
+	}
+
+	for _, option := range strings.Split(options, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(option), "=")
+		if key != "global" {
+			return variableOptions, fmt.Errorf("unknown option `%s`", key)
+		}
+
+		variableOptions.Global = true
+		if found {
+			global, err := strconv.ParseBool(value)
+			if err != nil {
+				return variableOptions, fmt.Errorf("global must be true or false, got `%s`", value)
+			}
+			variableOptions.Global = global
+		}
+	}
+
+	return variableOptions, nil
+}
+
+// ParseDurations parses the retry delay and timeout of the command. Durations that are not set are zero
+func (e ExecCommand) ParseDurations() (*ExecDurations, error) {
+	durations := &ExecDurations{}
+
+	for _, d := range []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"retryDelay", e.RetryDelay, &durations.RetryDelay},
+		{"timeout", e.Timeout, &durations.Timeout},
+	} {
+		if d.value == "" {
+			continue
+		}
+
+		duration, err := time.ParseDuration(d.value)
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("invalid command %s `%s`: expected a duration such as 30s", d.name, d.value)
+		}
+		*d.dest = duration
+	}
+
+	return durations, nil
+}
+
+// Validate checks that the retries, durations, user, and network mode of the command are valid
+func (e ExecCommand) Validate() error {
+	if e.Retries < 0 {
+		return fmt.Errorf("invalid command retries `%d`: must not be negative", e.Retries)
+	}
+
+	if _, err := e.ParseDurations(); err != nil {
+		return err
+	}
+
+	if e.User != "" {
+		name, group, hasGroup := strings.Cut(e.User, ":")
+		if !isUserOrID(name) || (hasGroup && !isUserOrID(group)) {
+			return fmt.Errorf("invalid command user `%s`: expected a user name or uid:gid", e.User)
+		}
+	}
+
+	switch e.Network {
+	case "", NetworkModeDefault, NetworkModeNone, NetworkModeHost:
+	default:
+		return fmt.Errorf("invalid command network `%s`: must be default, none, or host", e.Network)
+	}
+
+	return nil
+}
+
+func isUserOrID(s string) bool {
+	if id, err := strconv.Atoi(s); err == nil {
+		return id >= 0
+	}
+	return userNameRegex.MatchString(s)
 }
 
 func (e ExecCommand) IsSpread() bool {
@@ -218,3 +468,15 @@ func (c CopyCommand) IsSpread() bool {
 func (f FileCommand) IsSpread() bool {
 	return false
 }
+
+func (v VariableCommand) IsSpread() bool {
+	return false
+}
+
+// Validate checks that the variable has a valid environment variable name
+func (v VariableCommand) Validate() error {
+	if !variableNameRegex.MatchString(v.Name) {
+		return fmt.Errorf("invalid variable name `%s`: must start with a letter or underscore and only contain letters, numbers, and underscores", v.Name)
+	}
+	return nil
+}
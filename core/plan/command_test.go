@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/invopop/jsonschema"
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,6 +28,24 @@ func TestCommandMarshalUnmarshal(t *testing.T) {
 			expectedJSON:    `{"cmd":"sh -c 'echo hello'","customName":"Say Hello"}`,
 			unmarshalString: "RUN#Say Hello:echo hello",
 		},
+		{
+			name:            "exec command with retries and timeout",
+			command:         NewExecShellCommand("npm ci", ExecOptions{CustomName: "Install", Retries: 3, RetryDelay: "5s", Timeout: "10m"}),
+			expectedJSON:    `{"cmd":"sh -c 'npm ci'","customName":"Install","retries":3,"retryDelay":"5s","timeout":"10m"}`,
+			unmarshalString: "RUN[retries=3,retryDelay=5s,timeout=10m]#Install:npm ci",
+		},
+		{
+			name:            "exec command with directory, user, and network",
+			command:         NewExecShellCommand("npm test", ExecOptions{CustomName: "Test", Dir: "packages/api", User: "1000:1000", Network: NetworkModeNone}),
+			expectedJSON:    `{"cmd":"sh -c 'npm test'","customName":"Test","dir":"packages/api","user":"1000:1000","network":"none"}`,
+			unmarshalString: "RUN[dir=packages/api,user=1000:1000,network=none]#Test:npm test",
+		},
+		{
+			name:            "install command",
+			command:         NewInstallCommand("npm ci"),
+			expectedJSON:    `{"cmd":"npm ci","retries":2,"retryDelay":"5s"}`,
+			unmarshalString: "",
+		},
 
 		// Path
 		{
@@ -44,6 +63,20 @@ func TestCommandMarshalUnmarshal(t *testing.T) {
 			unmarshalString: "COPY:src.txt dst.txt",
 		},
 
+		// Variable
+		{
+			name:            "variable command",
+			command:         NewVariableCommand("JAVA_HOME", "/opt/java"),
+			expectedJSON:    `{"name":"JAVA_HOME","value":"/opt/java"}`,
+			unmarshalString: "ENV:JAVA_HOME=/opt/java",
+		},
+		{
+			name:            "global variable command",
+			command:         NewVariableCommand("DATABASE_URL", "postgres://localhost:5432/app?sslmode=disable", VariableOptions{Global: true}),
+			expectedJSON:    `{"name":"DATABASE_URL","value":"postgres://localhost:5432/app?sslmode=disable","global":true}`,
+			unmarshalString: "ENV[global]:DATABASE_URL=postgres://localhost:5432/app?sslmode=disable",
+		},
+
 		// File
 		{
 			name:            "file command without custom name",
@@ -89,3 +122,61 @@ func TestCommandMarshalUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalStringCommand(t *testing.T) {
+	t.Run("json encoded strings", func(t *testing.T) {
+		var step Step
+		err := json.Unmarshal([]byte(`{"commands":["PATH:/usr/local/bin","RUN[timeout=1m]:echo http://example.com","npm run build"]}`), &step)
+		require.NoError(t, err)
+
+		require.Equal(t, []Command{
+			PathCommand{Path: "/usr/local/bin"},
+			ExecCommand{Cmd: "sh -c 'echo http://example.com'", Timeout: "1m"},
+			ExecCommand{Cmd: "sh -c 'npm run build'", CustomName: "npm run build"},
+		}, step.Commands)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		for _, str := range []string{
+			"RUN[retries=many]:npm ci",
+			"RUN[attempts=3]:npm ci",
+			"RUN[retries]:npm ci",
+			"PATH[retries=3]:/usr/local/bin",
+			"ENV:JAVA_HOME",
+			"ENV[retries=3]:JAVA_HOME=/opt/java",
+			"ENV[global=maybe]:JAVA_HOME=/opt/java",
+		} {
+			_, err := UnmarshalCommand([]byte(str))
+			require.Error(t, err, str)
+		}
+	})
+}
+
+func TestExecCommandValidate(t *testing.T) {
+	require.NoError(t, ExecCommand{Cmd: "npm ci", Retries: 2, RetryDelay: "500ms", Timeout: "10m"}.Validate())
+
+	require.EqualError(t, ExecCommand{Cmd: "npm ci", Retries: -1}.Validate(), "invalid command retries `-1`: must not be negative")
+	require.EqualError(t, ExecCommand{Cmd: "npm ci", Timeout: "10"}.Validate(), "invalid command timeout `10`: expected a duration such as 30s")
+	require.EqualError(t, ExecCommand{Cmd: "npm ci", RetryDelay: "-5s"}.Validate(), "invalid command retryDelay `-5s`: expected a duration such as 30s")
+
+	require.NoError(t, ExecCommand{Cmd: "npm ci", User: "node", Network: NetworkModeHost}.Validate())
+	require.NoError(t, ExecCommand{Cmd: "npm ci", User: "1000:node"}.Validate())
+	require.EqualError(t, ExecCommand{Cmd: "npm ci", User: "1000:"}.Validate(), "invalid command user `1000:`: expected a user name or uid:gid")
+	require.EqualError(t, ExecCommand{Cmd: "npm ci", Network: "bridge"}.Validate(), "invalid command network `bridge`: must be default, none, or host")
+}
+
+func TestVariableCommandValidate(t *testing.T) {
+	require.NoError(t, VariableCommand{Name: "_JAVA_HOME2", Value: "/opt/java"}.Validate())
+	require.Error(t, VariableCommand{Name: "", Value: "/opt/java"}.Validate())
+	require.Error(t, VariableCommand{Name: "2FA", Value: "true"}.Validate())
+	require.Error(t, VariableCommand{Name: "JAVA-HOME", Value: "/opt/java"}.Validate())
+}
+
+func TestExecCommandJsonSchema(t *testing.T) {
+	schema := (&jsonschema.Reflector{DoNotReference: true}).Reflect(&ExecCommand{})
+
+	network, ok := schema.Properties.Get("network")
+	require.True(t, ok)
+	require.Equal(t, "The network mode of the command: default or none or host", network.Description)
+	require.Equal(t, []any{NetworkModeDefault, NetworkModeNone, NetworkModeHost}, network.Enum)
+}
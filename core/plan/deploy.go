@@ -0,0 +1,93 @@
+package plan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Healthcheck struct {
+	// The shell command to run to check that the container is healthy
+	Cmd string `json:"command" jsonschema:"description=The shell command to run to check that the container is healthy"`
+
+	// The time to wait between checks (e.g. 30s)
+	Interval string `json:"interval,omitempty" jsonschema:"description=The time to wait between checks (e.g. 30s)"`
+
+	// The time to wait before a check is considered to have failed
+	Timeout string `json:"timeout,omitempty" jsonschema:"description=The time to wait before a check is considered to have failed (e.g. 5s)"`
+
+	// The time to wait for the container to start before failed checks count
+	StartPeriod string `json:"startPeriod,omitempty" jsonschema:"description=The time to wait for the container to start before failed checks are counted (e.g. 10s)"`
+
+	// The number of consecutive failures needed to consider the container unhealthy
+	Retries int `json:"retries,omitempty" jsonschema:"description=The number of consecutive failures needed to consider the container unhealthy"`
+}
+
+// HealthcheckDurations are the parsed durations of a healthcheck
+type HealthcheckDurations struct {
+	Interval    time.Duration
+	Timeout     time.Duration
+	StartPeriod time.Duration
+}
+
+// ParseDurations parses the interval, timeout, and start period of the healthcheck.
+// Durations that are not set are zero
+func (h *Healthcheck) ParseDurations() (*HealthcheckDurations, error) {
+	durations := &HealthcheckDurations{}
+
+	for _, d := range []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"interval", h.Interval, &durations.Interval},
+		{"timeout", h.Timeout, &durations.Timeout},
+		{"startPeriod", h.StartPeriod, &durations.StartPeriod},
+	} {
+		if d.value == "" {
+			continue
+		}
+
+		duration, err := time.ParseDuration(d.value)
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("invalid healthcheck %s `%s`: expected a duration such as 30s", d.name, d.value)
+		}
+		*d.dest = duration
+	}
+
+	return durations, nil
+}
+
+// Validate checks that the healthcheck has a command and valid durations and retries
+func (h *Healthcheck) Validate() error {
+	if strings.TrimSpace(h.Cmd) == "" {
+		return fmt.Errorf("healthcheck command is required")
+	}
+
+	if h.Retries < 0 {
+		return fmt.Errorf("invalid healthcheck retries `%d`: must not be negative", h.Retries)
+	}
+
+	_, err := h.ParseDurations()
+	return err
+}
+
+// ParsePort normalizes a port (e.g. 80 or 53/udp) to the port/protocol format used in image configs
+func ParsePort(port string) (string, error) {
+	number, protocol, found := strings.Cut(strings.TrimSpace(port), "/")
+	if !found {
+		protocol = "tcp"
+	}
+
+	if protocol != "tcp" && protocol != "udp" && protocol != "sctp" {
+		return "", fmt.Errorf("invalid port `%s`: protocol must be tcp, udp, or sctp", port)
+	}
+
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("invalid port `%s`: expected a number between 1 and 65535", port)
+	}
+
+	return fmt.Sprintf("%d/%s", n, protocol), nil
+}
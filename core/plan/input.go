@@ -177,7 +177,7 @@ func (Input) JSONSchema() *jsonschema.Schema {
 	stringSchema := &jsonschema.Schema{
 		Type:        "string",
 		Description: "Strings will be parsed and interpreted as an input. Valid formats are: '.', '...', or '$step'",
-		Enum:        []interface{}{".", "...", "$step"},
+		Pattern:     `^(\.|\.\.\.|\$.+)$`,
 	}
 
 	availableInputs := []*jsonschema.Schema{stepSchema, imageSchema, localSchema, stringSchema}
@@ -0,0 +1,17 @@
+package plan
+
+// Standard OCI image labels
+// https://github.com/opencontainers/image-spec/blob/main/annotations.md
+const (
+	LabelCreated  = "org.opencontainers.image.created"
+	LabelRevision = "org.opencontainers.image.revision"
+)
+
+// Labels railpack adds to every image it builds
+const (
+	LabelRailpackVersion  = "com.railpack.version"
+	LabelRailpackProvider = "com.railpack.provider"
+
+	// Prefix of the labels that hold the command for each process type (e.g. com.railpack.process.web)
+	LabelProcessPrefix = "com.railpack.process."
+)
@@ -1,33 +1,99 @@
 package plan
 
+import (
+	"maps"
+
+	"github.com/invopop/jsonschema"
+)
+
 const (
 	RAILPACK_BUILDER_IMAGE = "ghcr.io/railwayapp/railpack-builder:latest"
 	RAILPACK_RUNTIME_IMAGE = "ghcr.io/railwayapp/railpack-runtime:latest"
 )
 
 type BuildPlan struct {
-	Steps   []Step            `json:"steps,omitempty"`
-	Caches  map[string]*Cache `json:"caches,omitempty"`
-	Secrets []string          `json:"secrets,omitempty"`
-	Deploy  Deploy            `json:"deploy,omitempty"`
+	Version int               `json:"version,omitempty" jsonschema:"description=The version of the plan format. Plans without a version are treated as version 0"`
+	Steps   []Step            `json:"steps,omitempty" jsonschema:"description=The steps to build. Each step is built from its inputs by running its commands"`
+	Caches  map[string]*Cache `json:"caches,omitempty" jsonschema:"description=The caches available to the steps. The key is the name that steps use to reference the cache"`
+	Secrets []string          `json:"secrets,omitempty" jsonschema:"description=The names of the secrets available to the steps"`
+	Deploy  Deploy            `json:"deploy,omitempty" jsonschema:"description=The final image that is deployed"`
 }
 
 type Deploy struct {
 	// The inputs for the deploy step
-	Inputs []Input `json:"inputs,omitempty"`
+	Inputs []Input `json:"inputs,omitempty" jsonschema:"description=The inputs for the deploy step"`
 
 	// The command to run in the container
-	StartCmd string `json:"startCommand,omitempty"`
+	StartCmd string `json:"startCommand,omitempty" jsonschema:"description=The command to run in the container"`
+
+	// The command to run once before a new release is started (e.g. database migrations)
+	ReleaseCmd string `json:"releaseCommand,omitempty" jsonschema:"description=The command to run once before a new release is started"`
 
 	// The variables available to this step. The key is the name of the variable that is referenced in a variable command
-	Variables map[string]string `json:"variables,omitempty"`
+	Variables map[string]string `json:"variables,omitempty" jsonschema:"description=The environment variables available in the container"`
 
 	// The paths to prepend to the $PATH environment variable
-	Paths []string `json:"paths,omitempty"`
+	Paths []string `json:"paths,omitempty" jsonschema:"description=The paths to prepend to the $PATH environment variable"`
+
+	// Named process types that can be run from the image (e.g. web, worker). The key is the process name and the value is the command
+	Processes map[string]string `json:"processes,omitempty" jsonschema:"description=Named process types that can be run from the image. The key is the process name and the value is the command"`
+
+	// The ports the container listens on (e.g. 80 or 53/udp)
+	Ports []string `json:"ports,omitempty" jsonschema:"description=The ports the container listens on (e.g. 80 or 53/udp)"`
+
+	// The command used to check that the container is healthy
+	Healthcheck *Healthcheck `json:"healthcheck,omitempty" jsonschema:"description=The command used to check that the container is healthy"`
+
+	// The signal sent to the container to stop it (e.g. SIGTERM)
+	StopSignal string `json:"stopSignal,omitempty" jsonschema:"description=The signal sent to the container to stop it (e.g. SIGTERM)"`
+
+	// The user to run the container as. Either a user name or uid:gid
+	User string `json:"user,omitempty" jsonschema:"description=The user to run the container as. Either a user name or uid:gid"`
+
+	// The labels to add to the final image
+	Labels map[string]string `json:"labels,omitempty" jsonschema:"description=The labels to add to the final image"`
+}
+
+// ImageLabels returns the labels for the final image. These are the process labels and the deploy labels,
+// with deploy labels taking precedence
+func (d *Deploy) ImageLabels() map[string]string {
+	labels := make(map[string]string, len(d.Processes)+len(d.Labels))
+	for name, cmd := range d.Processes {
+		labels[LabelProcessPrefix+name] = cmd
+	}
+	maps.Copy(labels, d.Labels)
+	return labels
+}
+
+func (BuildPlan) JSONSchemaExtend(schema *jsonschema.Schema) {
+	// Steps in a plan are a list, so unlike in the config the name is part of the step
+	stepsSchema, ok := schema.Properties.Get("steps")
+	if !ok || stepsSchema.Items == nil {
+		return
+	}
+
+	stepSchema := stepsSchema.Items
+	stepSchema.Properties.Set("name", &jsonschema.Schema{
+		Type:        "string",
+		Description: "The name of the step",
+	})
+	stepSchema.Required = append([]string{"name"}, stepSchema.Required...)
+}
+
+// GetJsonSchema returns the JSON schema for a serialized build plan
+func GetJsonSchema() *jsonschema.Schema {
+	r := jsonschema.Reflector{
+		DoNotReference: true,
+		Anonymous:      true,
+	}
+
+	schema := r.Reflect(&BuildPlan{})
+	return schema
 }
 
 func NewBuildPlan() *BuildPlan {
 	return &BuildPlan{
+		Version: PLAN_VERSION,
 		Steps:   []Step{},
 		Deploy:  Deploy{},
 		Caches:  make(map[string]*Cache),
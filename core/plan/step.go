@@ -12,7 +12,7 @@ type Step struct {
 	Commands  []Command         `json:"commands,omitempty" jsonschema:"description=The commands to run in this step"`
 	Secrets   []string          `json:"secrets,omitempty" jsonschema:"description=The secrets that this step uses"`
 	Assets    map[string]string `json:"assets,omitempty" jsonschema:"description=The assets available to this step. The key is the name of the asset that is referenced in a file command"`
-	Variables map[string]string `json:"variables,omitempty" jsonschema:"description=The variables available to this step. The key is the name of the variable that is referenced in a variable command"`
+	Variables map[string]string `json:"variables,omitempty" jsonschema:"description=The environment variables available to this step and the steps that use it. Variables can also be set partway through a step with a variable command"`
 	Caches    []string          `json:"caches,omitempty" jsonschema:"description=The caches available to all commands in this step. Each cache must refer to a cache at the top level of the plan"`
 }
 
@@ -90,8 +90,9 @@ func CommandsSchema() *jsonschema.Schema {
 	pathSchema := generateSchemaWithComments(PathCommand{})
 	copySchema := generateSchemaWithComments(CopyCommand{})
 	fileSchema := generateSchemaWithComments(FileCommand{})
+	variableSchema := generateSchemaWithComments(VariableCommand{})
 
-	availableCommands := []*jsonschema.Schema{execSchema, pathSchema, copySchema, fileSchema}
+	availableCommands := []*jsonschema.Schema{execSchema, pathSchema, copySchema, fileSchema, variableSchema}
 
 	// Add string schema type as an additional valid command type
 	stringSchema := &jsonschema.Schema{
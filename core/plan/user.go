@@ -0,0 +1,103 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// The user providers run the deployed container as by default
+	RAILPACK_USER = "railpack"
+
+	// The uid and gid used when creating a user that is specified by name
+	RAILPACK_USER_ID = 1000
+)
+
+var userNameRegex = regexp.MustCompile(`^[a-z_][a-z0-9_-]*$`)
+
+// The ids of the system accounts that already exist in the Debian based runtime images
+var systemUserIDs = map[string]int{
+	"daemon":   1,
+	"www-data": 33,
+	"nobody":   65534,
+}
+
+// User is the user the deployed container runs as
+type User struct {
+	Name string
+	UID  int
+	GID  int
+
+	// Whether the user was specified by name, in which case an existing account with that name is used
+	byName bool
+}
+
+// ParseUser parses a deploy user specified either by name (e.g. "railpack") or by uid and gid (e.g. "1000:1000").
+// Users specified by name get the ids of the system account with that name (e.g. www-data) or the default uid and gid
+func ParseUser(spec string) (*User, error) {
+	spec = strings.TrimSpace(spec)
+
+	if spec == "root" {
+		return &User{Name: "root", UID: 0, GID: 0}, nil
+	}
+
+	if uidStr, gidStr, found := strings.Cut(spec, ":"); found {
+		uid, err := strconv.Atoi(uidStr)
+		if err != nil || uid < 0 {
+			return nil, fmt.Errorf("invalid user `%s`: uid must be a non-negative number", spec)
+		}
+
+		gid, err := strconv.Atoi(gidStr)
+		if err != nil || gid < 0 {
+			return nil, fmt.Errorf("invalid user `%s`: gid must be a non-negative number", spec)
+		}
+
+		name := RAILPACK_USER
+		if uid == 0 {
+			name = "root"
+		}
+
+		return &User{Name: name, UID: uid, GID: gid}, nil
+	}
+
+	if !userNameRegex.MatchString(spec) {
+		return nil, fmt.Errorf("invalid user `%s`: expected a user name or uid:gid", spec)
+	}
+
+	if id, ok := systemUserIDs[spec]; ok {
+		return &User{Name: spec, UID: id, GID: id, byName: true}, nil
+	}
+
+	return &User{Name: spec, UID: RAILPACK_USER_ID, GID: RAILPACK_USER_ID, byName: true}, nil
+}
+
+// IsRoot returns true if the container runs as root and no user needs to be created
+func (u *User) IsRoot() bool {
+	return u.UID == 0
+}
+
+// String returns the numeric uid:gid so that runtimes can verify the user is not root
+func (u *User) String() string {
+	return fmt.Sprintf("%d:%d", u.UID, u.GID)
+}
+
+// CreateCommand returns the shell command that adds the user and group to an image if they do not exist yet.
+// Existing accounts are looked up by name first and then by id. A user specified by name that already exists
+// with a different uid fails the command, since the files would otherwise be owned by the wrong user
+func (u *User) CreateCommand(homeDir string) string {
+	command := fmt.Sprintf(
+		"(getent group %[3]s >/dev/null || getent group %[2]d >/dev/null || groupadd --gid %[2]d %[3]s) && (getent passwd %[3]s >/dev/null || getent passwd %[1]d >/dev/null || useradd --uid %[1]d --gid %[2]d --home-dir %[4]s --no-create-home --shell /bin/sh %[3]s)",
+		u.UID, u.GID, u.Name, homeDir,
+	)
+
+	if u.byName {
+		command += fmt.Sprintf(
+			` && (! getent passwd %[2]s >/dev/null || [ "$(id -u %[2]s)" = "%[1]d" ] || { echo "railpack: user %[2]s already exists with uid $(id -u %[2]s). Set the deploy user to its uid:gid instead" >&2; exit 1; })`,
+			u.UID, u.Name,
+		)
+	}
+
+	return command
+}
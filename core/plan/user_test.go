@@ -0,0 +1,60 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUser(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected *User
+		wantErr  bool
+	}{
+		{spec: "railpack", expected: &User{Name: "railpack", UID: 1000, GID: 1000, byName: true}},
+		{spec: "app", expected: &User{Name: "app", UID: 1000, GID: 1000, byName: true}},
+		{spec: "www-data", expected: &User{Name: "www-data", UID: 33, GID: 33, byName: true}},
+		{spec: "nobody", expected: &User{Name: "nobody", UID: 65534, GID: 65534, byName: true}},
+		{spec: "1001:1002", expected: &User{Name: RAILPACK_USER, UID: 1001, GID: 1002}},
+		{spec: "root", expected: &User{Name: "root", UID: 0, GID: 0}},
+		{spec: "0:0", expected: &User{Name: "root", UID: 0, GID: 0}},
+		{spec: "1000", wantErr: true},
+		{spec: "abc:1000", wantErr: true},
+		{spec: "1000:-1", wantErr: true},
+		{spec: "Not A User", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			user, err := ParseUser(tt.spec)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, user)
+		})
+	}
+}
+
+func TestUserCreateCommand(t *testing.T) {
+	user, err := ParseUser("www-data")
+	require.NoError(t, err)
+	require.Equal(t,
+		`(getent group www-data >/dev/null || getent group 33 >/dev/null || groupadd --gid 33 www-data) && `+
+			`(getent passwd www-data >/dev/null || getent passwd 33 >/dev/null || useradd --uid 33 --gid 33 --home-dir /app --no-create-home --shell /bin/sh www-data) && `+
+			`(! getent passwd www-data >/dev/null || [ "$(id -u www-data)" = "33" ] || { echo "railpack: user www-data already exists with uid $(id -u www-data). Set the deploy user to its uid:gid instead" >&2; exit 1; })`,
+		user.CreateCommand("/app"),
+	)
+
+	// Users specified by id reuse any existing account with that id
+	user, err = ParseUser("1001:1002")
+	require.NoError(t, err)
+	require.Equal(t,
+		`(getent group railpack >/dev/null || getent group 1002 >/dev/null || groupadd --gid 1002 railpack) && `+
+			`(getent passwd railpack >/dev/null || getent passwd 1001 >/dev/null || useradd --uid 1001 --gid 1002 --home-dir /app --no-create-home --shell /bin/sh railpack)`,
+		user.CreateCommand("/app"),
+	)
+}
@@ -0,0 +1,125 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"strings"
+
+	"github.com/railwayapp/railpack/internal/utils"
+)
+
+// The version of the serialized plan format. Bump it and add a migration whenever the format changes
+const PLAN_VERSION = 4
+
+// planMigration upgrades a decoded plan by one version
+type planMigration func(plan map[string]any) error
+
+// planMigrations[i] upgrades a plan from version i to version i+1
+var planMigrations = []planMigration{
+	migrateRelativeCacheDirectories,
+	// Version 2 added retries, retryDelay, and timeout to exec commands
+	noMigration,
+	// Version 3 added dir, user, and network to exec commands
+	noMigration,
+	// Version 4 added variable commands
+	noMigration,
+}
+
+// MigratePlan upgrades a serialized plan to PLAN_VERSION. Plans without a version are treated as version 0.
+// Plans from a newer version of Railpack are accepted as long as they only use fields this version knows about
+func MigratePlan(data []byte) ([]byte, error) {
+	var plan map[string]any
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, err
+	}
+
+	version, err := getPlanVersion(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	if version > PLAN_VERSION {
+		if err := checkUnknownFields(plan, version); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+
+	if version == PLAN_VERSION {
+		return data, nil
+	}
+
+	for v := version; v < PLAN_VERSION; v++ {
+		if err := planMigrations[v](plan); err != nil {
+			return nil, fmt.Errorf("failed to migrate plan from version %d to %d: %w", v, v+1, err)
+		}
+	}
+
+	plan["version"] = PLAN_VERSION
+
+	return json.Marshal(plan)
+}
+
+func getPlanVersion(plan map[string]any) (int, error) {
+	rawVersion, ok := plan["version"]
+	if !ok {
+		return 0, nil
+	}
+
+	version, ok := rawVersion.(float64)
+	if !ok || version < 0 || version != math.Trunc(version) {
+		return 0, fmt.Errorf("plan version must be a non-negative integer, got %v", rawVersion)
+	}
+
+	return int(version), nil
+}
+
+// checkUnknownFields rejects a plan from a newer version of Railpack if it uses fields that would otherwise be silently ignored
+func checkUnknownFields(plan map[string]any, version int) error {
+	schemaErrors, err := utils.ValidateJSONSchema(GetJsonSchema(), plan)
+	if err != nil {
+		return err
+	}
+
+	unknown := []string{}
+	for _, schemaError := range schemaErrors {
+		if strings.HasPrefix(schemaError.Message, "unknown property") {
+			unknown = append(unknown, schemaError.Pointer)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("plan version %d is newer than the latest supported version %d and uses unsupported fields (%s). Upgrade Railpack to build this plan", version, PLAN_VERSION, strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// noMigration is used for versions that only added optional fields, which older plans do not need
+func noMigration(plan map[string]any) error {
+	return nil
+}
+
+// migrateRelativeCacheDirectories makes cache directories absolute.
+// Before version 1, cache directories could be relative to the /app working directory
+func migrateRelativeCacheDirectories(plan map[string]any) error {
+	caches, ok := plan["caches"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	for _, rawCache := range caches {
+		cache, ok := rawCache.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if directory, ok := cache["directory"].(string); ok && directory != "" && !strings.HasPrefix(directory, "/") {
+			cache["directory"] = path.Join("/app", directory)
+		}
+	}
+
+	return nil
+}
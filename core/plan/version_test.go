@@ -0,0 +1,64 @@
+package plan
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigratePlan(t *testing.T) {
+	t.Run("current version is unchanged", func(t *testing.T) {
+		data, err := json.Marshal(NewBuildPlan())
+		require.NoError(t, err)
+
+		migrated, err := MigratePlan(data)
+		require.NoError(t, err)
+		require.Equal(t, data, migrated)
+	})
+
+	t.Run("unversioned plan is migrated", func(t *testing.T) {
+		data := []byte(`{
+			"caches": {
+				"vite": {"directory": "node_modules/.vite", "type": "shared"},
+				"npm": {"directory": "/root/.npm", "type": "shared"}
+			},
+			"deploy": {"startCommand": "npm start"}
+		}`)
+
+		migrated, err := MigratePlan(data)
+		require.NoError(t, err)
+
+		buildPlan := &BuildPlan{}
+		require.NoError(t, json.Unmarshal(migrated, buildPlan))
+		require.Equal(t, PLAN_VERSION, buildPlan.Version)
+		require.Equal(t, "/app/node_modules/.vite", buildPlan.Caches["vite"].Directory)
+		require.Equal(t, "/root/.npm", buildPlan.Caches["npm"].Directory)
+		require.Equal(t, "npm start", buildPlan.Deploy.StartCmd)
+	})
+
+	t.Run("newer version with known fields is accepted", func(t *testing.T) {
+		data := []byte(`{"version": 999, "deploy": {"startCommand": "npm start"}}`)
+		migrated, err := MigratePlan(data)
+		require.NoError(t, err)
+		require.Equal(t, data, migrated)
+	})
+
+	t.Run("newer version with unknown fields is rejected", func(t *testing.T) {
+		_, err := MigratePlan([]byte(`{"version": 999, "deploy": {"startCommand": "npm start", "entrypoint": "/bin/sh"}}`))
+		require.ErrorContains(t, err, "plan version 999 is newer than the latest supported version")
+		require.ErrorContains(t, err, "/deploy/entrypoint")
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		_, err := MigratePlan([]byte(`{"version": "1"}`))
+		require.Error(t, err)
+
+		_, err = MigratePlan([]byte(`{"version": 1.5}`))
+		require.Error(t, err)
+	})
+
+	t.Run("every version has a migration", func(t *testing.T) {
+		require.Len(t, planMigrations, PLAN_VERSION)
+	})
+}
@@ -2,6 +2,8 @@ package core
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -13,6 +15,7 @@ import (
 
 const (
 	AnsiRed           = "1"
+	AnsiGreen         = "2"
 	AnsiYellow        = "3"
 	AnsiBlue          = "4"
 	AnsiMagenta       = "5"
@@ -91,6 +94,15 @@ var (
 
 	metadataValueStyle = lipgloss.NewStyle().
 				Bold(true)
+
+	diffAddedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(AnsiGreen))
+
+	diffRemovedStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(AnsiRed))
+
+	diffChangedStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(AnsiYellow))
 )
 
 type PrintOptions struct {
@@ -112,6 +124,7 @@ func FormatBuildResult(br *BuildResult, options ...PrintOptions) string {
 
 	formatHeader(&output, opts.Version)
 	formatLogs(&output, br.Logs)
+	formatConfig(&output, br)
 	formatPackages(&output, br.ResolvedPackages)
 	formatSteps(&output, br)
 	formatDeploy(&output, br)
@@ -161,6 +174,48 @@ func formatLogs(output *strings.Builder, logs []logger.Msg) {
 	}
 }
 
+// formatConfig shows the config environment that was applied and,
+// when several config files were merged, which file each config value came from
+func formatConfig(output *strings.Builder, br *BuildResult) {
+	files := map[string]bool{}
+	for _, source := range br.ConfigSources {
+		files[source] = true
+	}
+
+	showSources := len(files) > 1 || (br.Environment != "" && len(files) > 0)
+	if br.Environment == "" && !showSources {
+		return
+	}
+
+	output.WriteString(sectionHeaderStyle.MarginTop(1).Render("Config"))
+	output.WriteString("\n")
+
+	if br.Environment != "" {
+		separator := metadataSeparatorStyle.Render(":")
+		output.WriteString(metadataStyle.Render(fmt.Sprintf("environment%s%s", separator, metadataValueStyle.Render(br.Environment))))
+		output.WriteString("\n")
+	}
+
+	if !showSources {
+		return
+	}
+
+	keys := slices.Sorted(maps.Keys(br.ConfigSources))
+
+	keyWidth := 1
+	for _, key := range keys {
+		keyWidth = max(keyWidth, len(key))
+	}
+
+	localKeyStyle := packageNameStyle.Width(keyWidth)
+	separator := separatorStyle.Render("│")
+
+	for _, key := range keys {
+		output.WriteString(fmt.Sprintf("%s%s%s", localKeyStyle.Render(key), separator, sourceStyle.Render(br.ConfigSources[key])))
+		output.WriteString("\n")
+	}
+}
+
 func formatPackages(output *strings.Builder, packages map[string]*resolver.ResolvedPackage) {
 	if len(packages) == 0 {
 		return
@@ -230,10 +285,45 @@ func formatSteps(output *strings.Builder, br *BuildResult) {
 }
 
 func formatDeploy(output *strings.Builder, br *BuildResult) {
-	if br.Plan != nil && br.Plan.Deploy.StartCmd != "" {
-		output.WriteString(sectionHeaderStyle.MarginTop(1).Render("Deploy"))
+	if br.Plan == nil {
+		return
+	}
+
+	deploy := br.Plan.Deploy
+
+	// The release command and processes are printed as named commands below the start command
+	names := []string{}
+	commands := map[string]string{}
+	if deploy.ReleaseCmd != "" {
+		names = append(names, "release")
+		commands["release"] = deploy.ReleaseCmd
+	}
+	for _, name := range slices.Sorted(maps.Keys(deploy.Processes)) {
+		names = append(names, name)
+		commands[name] = deploy.Processes[name]
+	}
+
+	if deploy.StartCmd == "" && len(names) == 0 {
+		return
+	}
+
+	output.WriteString(sectionHeaderStyle.MarginTop(1).Render("Deploy"))
+	output.WriteString("\n")
+
+	if deploy.StartCmd != "" {
+		output.WriteString(fmt.Sprintf("%s %s", commandPrefixStyle.Render("$"), commandStyle.Render(deploy.StartCmd)))
+	}
+
+	for i, name := range names {
+		headerStyle := indentedStepHeaderStyle
+		if i > 0 || deploy.StartCmd != "" {
+			output.WriteString("\n")
+			headerStyle = headerStyle.MarginTop(1)
+		}
+
+		output.WriteString(headerStyle.Render(fmt.Sprintf("▸ %s", name)))
 		output.WriteString("\n")
-		output.WriteString(fmt.Sprintf("%s %s", commandPrefixStyle.Render("$"), commandStyle.Render(br.Plan.Deploy.StartCmd)))
+		output.WriteString(fmt.Sprintf("%s %s", commandPrefixStyle.Render("$"), commandStyle.Render(commands[name])))
 	}
 }
 
@@ -297,3 +387,45 @@ func max(a, b int) int {
 	}
 	return b
 }
+
+// FormatChanges renders the changes between two build results
+func FormatChanges(changes []Change) string {
+	var output strings.Builder
+
+	output.WriteString(sectionHeaderStyle.Render("Changes"))
+	output.WriteString("\n")
+
+	if len(changes) == 0 {
+		output.WriteString(logInfoStyle.Render("No differences"))
+		output.WriteString("\n")
+		return output.String()
+	}
+
+	for _, change := range changes {
+		switch change.Type {
+		case ChangeAdded:
+			output.WriteString(diffAddedStyle.Render(fmt.Sprintf("+ %s", change.Path)))
+		case ChangeRemoved:
+			output.WriteString(diffRemovedStyle.Render(fmt.Sprintf("- %s", change.Path)))
+		default:
+			output.WriteString(diffChangedStyle.Render(fmt.Sprintf("~ %s", change.Path)))
+		}
+		output.WriteString("\n")
+
+		formatChangeValue(&output, diffRemovedStyle.Render("-"), change.Old)
+		formatChangeValue(&output, diffAddedStyle.Render("+"), change.New)
+	}
+
+	return output.String()
+}
+
+// formatChangeValue writes each line of a value (e.g. the commands of a removed step) indented below the path
+func formatChangeValue(output *strings.Builder, prefix string, value string) {
+	if value == "" {
+		return
+	}
+
+	for _, line := range strings.Split(strings.TrimRight(value, "\n"), "\n") {
+		output.WriteString(fmt.Sprintf("    %s %s\n", prefix, line))
+	}
+}
@@ -78,7 +78,7 @@ func (p *DenoProvider) Build(ctx *generate.GenerateContext, build *generate.Comm
 
 	build.AddCommands([]plan.Command{
 		plan.NewCopyCommand("."),
-		plan.NewExecCommand(fmt.Sprintf("deno cache %s", p.mainFile)),
+		plan.NewInstallCommand(fmt.Sprintf("deno cache %s", p.mainFile)),
 	})
 }
 
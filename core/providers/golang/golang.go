@@ -114,7 +114,7 @@ func (p *GoProvider) InstallGoDeps(ctx *generate.GenerateContext, install *gener
 		install.AddCommand(plan.NewCopyCommand("go.sum"))
 	}
 
-	install.AddCommand(plan.NewExecCommand("go mod download"))
+	install.AddCommand(plan.NewInstallCommand("go mod download"))
 
 	ctx.Logger.LogInfo("Using go mod")
 
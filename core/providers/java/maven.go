@@ -17,7 +17,7 @@ func (p *JavaProvider) getMavenExe(ctx *generate.GenerateContext) string {
 }
 
 func (p *JavaProvider) mavenCache(ctx *generate.GenerateContext) string {
-	return ctx.Caches.AddCache(MAVEN_CACHE_KEY, ".m2/repository")
+	return ctx.Caches.AddCache(MAVEN_CACHE_KEY, "/app/.m2/repository")
 }
 
 func getMavenPortConfig(ctx *generate.GenerateContext) string {
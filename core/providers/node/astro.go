@@ -71,5 +71,5 @@ func (p *NodeProvider) getAstroEnvVars() map[string]string {
 }
 
 func (p *NodeProvider) getAstroCache(ctx *generate.GenerateContext) string {
-	return ctx.Caches.AddCache("astro", "node_modules/.astro")
+	return ctx.Caches.AddCache("astro", "/app/node_modules/.astro")
 }
@@ -76,10 +76,10 @@ func (p *NodeProvider) Plan(ctx *generate.GenerateContext) error {
 	p.InstallNodeDeps(ctx, install)
 
 	// Prune
-	prune := ctx.NewCommandStep("prune")
-	prune.AddInput(plan.NewStepInput(install.Name()))
-	prune.Secrets = []string{}
+	var prune *generate.CommandStepBuilder
 	if p.shouldPrune(ctx) && !isSPA {
+		prune = ctx.NewCommandStep("prune")
+		prune.AddInput(plan.NewStepInput(install.Name()))
 		p.PruneNodeDeps(ctx, prune)
 	}
 
@@ -118,7 +118,7 @@ func (p *NodeProvider) Plan(ctx *generate.GenerateContext) error {
 	nodeModulesInput := plan.NewStepInput(build.Name(), plan.InputOptions{
 		Include: p.packageManager.GetInstallFolder(ctx),
 	})
-	if p.shouldPrune(ctx) {
+	if prune != nil {
 		nodeModulesInput = plan.NewStepInput(prune.Name(), plan.InputOptions{
 			Include: p.packageManager.GetInstallFolder(ctx),
 		})
@@ -197,7 +197,7 @@ func (p *NodeProvider) addCaches(ctx *generate.GenerateContext, build *generate.
 	}
 
 	if p.isRemix() {
-		build.AddCache(ctx.Caches.AddCache("remix", ".cache"))
+		build.AddCache(ctx.Caches.AddCache("remix", "/app/.cache"))
 	}
 
 	if p.isAstro(ctx) {
@@ -156,3 +156,19 @@ func TestGetNextApps(t *testing.T) {
 		})
 	}
 }
+
+func TestNodePrune(t *testing.T) {
+	ctx := testingUtils.CreateGenerateContext(t, "../../../examples/node-npm")
+	provider := NodeProvider{}
+	require.NoError(t, provider.Initialize(ctx))
+	require.NoError(t, provider.Plan(ctx))
+
+	// The prune step is only added when dependencies are pruned
+	require.Nil(t, ctx.GetStepByName("prune"))
+
+	ctx = testingUtils.CreateGenerateContext(t, "../../../examples/node-npm")
+	ctx.Env.SetVariable("RAILPACK_PRUNE_DEPS", "true")
+	require.NoError(t, provider.Initialize(ctx))
+	require.NoError(t, provider.Plan(ctx))
+	require.NotNil(t, ctx.GetStepByName("prune"))
+}
@@ -93,18 +93,18 @@ func (p PackageManager) InstallDeps(ctx *generate.GenerateContext, install *gene
 	case PackageManagerNpm:
 		hasLockfile := ctx.App.HasMatch("package-lock.json")
 		if hasLockfile {
-			install.AddCommand(plan.NewExecCommand("npm ci"))
+			install.AddCommand(plan.NewInstallCommand("npm ci"))
 		} else {
-			install.AddCommand(plan.NewExecCommand("npm install"))
+			install.AddCommand(plan.NewInstallCommand("npm install"))
 		}
 	case PackageManagerPnpm:
-		install.AddCommand(plan.NewExecCommand("pnpm install --frozen-lockfile --prefer-offline"))
+		install.AddCommand(plan.NewInstallCommand("pnpm install --frozen-lockfile --prefer-offline"))
 	case PackageManagerBun:
-		install.AddCommand(plan.NewExecCommand("bun install --frozen-lockfile"))
+		install.AddCommand(plan.NewInstallCommand("bun install --frozen-lockfile"))
 	case PackageManagerYarn1:
-		install.AddCommand(plan.NewExecCommand("yarn install --frozen-lockfile"))
+		install.AddCommand(plan.NewInstallCommand("yarn install --frozen-lockfile"))
 	case PackageManagerYarn2:
-		install.AddCommand(plan.NewExecCommand("yarn install --check-cache"))
+		install.AddCommand(plan.NewInstallCommand("yarn install --check-cache"))
 	}
 }
 
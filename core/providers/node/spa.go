@@ -92,6 +92,7 @@ func (p *NodeProvider) DeploySPA(ctx *generate.GenerateContext, build *generate.
 	}
 
 	ctx.Deploy.StartCmd = fmt.Sprintf("caddy run --config %s --adapter caddyfile 2>&1", DefaultCaddyfilePath)
+	ctx.Deploy.Ports = []string{"8080"}
 
 	ctx.Deploy.Inputs = []plan.Input{
 		ctx.DefaultRuntimeInput(),
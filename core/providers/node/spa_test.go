@@ -3,6 +3,7 @@ package node
 import (
 	"testing"
 
+	"github.com/railwayapp/railpack/core/plan"
 	testingUtils "github.com/railwayapp/railpack/core/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -148,3 +149,15 @@ func TestHasCustomStartCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestDeploySPA(t *testing.T) {
+	ctx := testingUtils.CreateGenerateContext(t, "../../../examples/node-vite-react")
+	provider := NodeProvider{}
+	require.NoError(t, provider.Initialize(ctx))
+
+	require.NoError(t, provider.DeploySPA(ctx, ctx.NewCommandStep("build")))
+
+	// Caddy listens on an unprivileged port so that the default non-root user can run it
+	require.Equal(t, []string{"8080"}, ctx.Deploy.Ports)
+	require.Equal(t, plan.RAILPACK_USER, ctx.Deploy.User)
+}
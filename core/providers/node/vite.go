@@ -62,7 +62,7 @@ func (p *NodeProvider) getViteOutputDirectory(ctx *generate.GenerateContext) str
 }
 
 func (p *NodeProvider) getViteCache(ctx *generate.GenerateContext) string {
-	return ctx.Caches.AddCache("vite", "node_modules/.vite")
+	return ctx.Caches.AddCache("vite", "/app/node_modules/.vite")
 }
 
 func (p *NodeProvider) isSvelteKit() bool {
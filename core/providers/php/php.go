@@ -18,6 +18,8 @@ const (
 	DEFAULT_PHP_VERSION  = "8.4"
 	DefaultCaddyfilePath = "/Caddyfile"
 	COMPOSER_CACHE_DIR   = "/opt/cache/composer"
+	CADDY_CONFIG_DIR     = "/config/caddy"
+	CADDY_DATA_DIR       = "/data/caddy"
 )
 
 //go:embed Caddyfile
@@ -97,6 +99,11 @@ func (p *PhpProvider) Plan(ctx *generate.GenerateContext) error {
 
 	ctx.Deploy.StartCmd = "/start-container.sh"
 
+	if isLaravel {
+		ctx.Deploy.ReleaseCmd = "php artisan migrate --force"
+	}
+	ctx.Deploy.Ports = []string{"8080"}
+
 	return nil
 }
 
@@ -123,7 +130,7 @@ func (p *PhpProvider) Prepare(ctx *generate.GenerateContext, prepare *generate.C
 		"APP_LOCALE":    "en",
 		"LOG_CHANNEL":   "stderr",
 		"LOG_LEVEL":     "debug",
-		"SERVER_NAME":   ":80",
+		"SERVER_NAME":   ":8080",
 		"PHP_INI_DIR":   "/usr/local/etc/php",
 		"OCTANE_SERVER": "frankenphp",
 		"IS_LARAVEL":    strconv.FormatBool(p.usesLaravel(ctx)),
@@ -138,15 +145,36 @@ func (p *PhpProvider) Prepare(ctx *generate.GenerateContext, prepare *generate.C
 			Mode:       0755,
 		}),
 	})
+
+	// FrankenPHP stores its Caddy config and data in directories of the base image that are owned by root
+	if owner := p.deployOwner(ctx); owner != "" {
+		prepare.AddCommand(plan.NewExecCommand(fmt.Sprintf("mkdir -p %[1]s %[2]s && chown -R %[3]s %[1]s %[2]s", CADDY_CONFIG_DIR, CADDY_DATA_DIR, owner)))
+	}
+
 	prepare.Secrets = []string{}
 }
 
+// deployOwner returns the uid:gid of the user the container runs as, or an empty string if it runs as root
+func (p *PhpProvider) deployOwner(ctx *generate.GenerateContext) string {
+	spec := plan.RAILPACK_USER
+	if ctx.Config.Deploy != nil && ctx.Config.Deploy.User != "" {
+		spec = ctx.Config.Deploy.User
+	}
+
+	user, err := plan.ParseUser(spec)
+	if err != nil || user.IsRoot() {
+		return ""
+	}
+
+	return user.String()
+}
+
 func (p *PhpProvider) InstallExtensions(ctx *generate.GenerateContext, extensions *generate.CommandStepBuilder) {
 	phpExtensions := p.getPhpExtensions(ctx)
 
 	if len(phpExtensions) > 0 {
 		extensions.AddCommands([]plan.Command{
-			plan.NewExecCommand(fmt.Sprintf("install-php-extensions %s", strings.Join(phpExtensions, " "))),
+			plan.NewInstallCommand(fmt.Sprintf("install-php-extensions %s", strings.Join(phpExtensions, " "))),
 		})
 		extensions.Caches = append(extensions.Caches, ctx.Caches.GetAptCaches()...)
 	}
@@ -172,7 +200,7 @@ func (p *PhpProvider) InstallCompose(ctx *generate.GenerateContext, composer *ge
 		}
 
 		composer.AddCommands([]plan.Command{
-			plan.NewExecCommand("composer install --optimize-autoloader --no-scripts --no-interaction"),
+			plan.NewInstallCommand("composer install --optimize-autoloader --no-scripts --no-interaction"),
 		})
 	}
 }
@@ -3,6 +3,7 @@ package php
 import (
 	"testing"
 
+	"github.com/railwayapp/railpack/core/plan"
 	testingUtils "github.com/railwayapp/railpack/core/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -48,3 +49,20 @@ func TestPhpProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestPhpDeployUser(t *testing.T) {
+	ctx := testingUtils.CreateGenerateContext(t, "../../../examples/php-vanilla")
+	provider := PhpProvider{}
+	require.NoError(t, provider.Plan(ctx))
+
+	// FrankenPHP listens on an unprivileged port so that the default non-root user can run it
+	require.Equal(t, []string{"8080"}, ctx.Deploy.Ports)
+	require.Equal(t, plan.RAILPACK_USER, ctx.Deploy.User)
+	require.Equal(t, "1000:1000", provider.deployOwner(ctx))
+
+	ctx.Config.Deploy.User = "www-data"
+	require.Equal(t, "33:33", provider.deployOwner(ctx))
+
+	ctx.Config.Deploy.User = "root"
+	require.Empty(t, provider.deployOwner(ctx))
+}
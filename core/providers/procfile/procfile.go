@@ -18,6 +18,18 @@ func (p *ProcfileProvider) Plan(ctx *generate.GenerateContext) (bool, error) {
 		return false, err
 	}
 
+	// The release command is run once per release rather than as a process
+	if releaseCommand := parsedProcfile["release"]; releaseCommand != "" {
+		ctx.Logger.LogInfo("Found release command in Procfile")
+		ctx.Deploy.ReleaseCmd = releaseCommand
+	}
+
+	for name, cmd := range parsedProcfile {
+		if name != "release" {
+			ctx.Deploy.Processes[name] = cmd
+		}
+	}
+
 	webCommand := parsedProcfile["web"]
 	workerCommand := parsedProcfile["worker"]
 
@@ -1,6 +1,8 @@
 package procfile
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	testingUtils "github.com/railwayapp/railpack/core/testing"
@@ -15,4 +17,25 @@ func TestProcfile(t *testing.T) {
 	require.NoError(t, err)
 
 	require.Equal(t, "gunicorn --bind 0.0.0.0:3333 main:app", ctx.Deploy.StartCmd)
+	require.Equal(t, map[string]string{"web": "gunicorn --bind 0.0.0.0:3333 main:app"}, ctx.Deploy.Processes)
+}
+
+func TestProcfileMultipleProcesses(t *testing.T) {
+	appDir := t.TempDir()
+	procfile := "web: npm start\nworker: npm run worker\nscheduler: npm run scheduler\nrelease: npm run migrate\n"
+	require.NoError(t, os.WriteFile(filepath.Join(appDir, "Procfile"), []byte(procfile), 0644))
+
+	ctx := testingUtils.CreateGenerateContext(t, appDir)
+	provider := ProcfileProvider{}
+
+	_, err := provider.Plan(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, "npm start", ctx.Deploy.StartCmd)
+	require.Equal(t, "npm run migrate", ctx.Deploy.ReleaseCmd)
+	require.Equal(t, map[string]string{
+		"web":       "npm start",
+		"worker":    "npm run worker",
+		"scheduler": "npm run scheduler",
+	}, ctx.Deploy.Processes)
 }
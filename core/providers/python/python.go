@@ -70,6 +70,10 @@ func (p *PythonProvider) Plan(ctx *generate.GenerateContext) error {
 	ctx.Deploy.StartCmd = p.GetStartCommand(ctx)
 	maps.Copy(ctx.Deploy.Variables, p.GetPythonEnvVars(ctx))
 
+	if p.isDjango(ctx) {
+		ctx.Deploy.ReleaseCmd = "python manage.py migrate"
+	}
+
 	installArtifacts := plan.NewStepInput(build.Name(), plan.InputOptions{
 		Include: installOutputs,
 	})
@@ -145,13 +149,13 @@ func (p *PythonProvider) InstallUv(ctx *generate.GenerateContext, install *gener
 	install.AddEnvVars(p.GetPythonEnvVars(ctx))
 	install.AddCommands([]plan.Command{
 		plan.NewPathCommand(LOCAL_BIN_PATH),
-		plan.NewExecCommand("pipx install uv"),
+		plan.NewInstallCommand("pipx install uv"),
 		plan.NewPathCommand(VENV_PATH + "/bin"),
 		plan.NewCopyCommand("pyproject.toml"),
 		plan.NewCopyCommand("uv.lock"),
-		plan.NewExecCommand("uv sync --locked --no-dev --no-install-project"),
+		plan.NewInstallCommand("uv sync --locked --no-dev --no-install-project"),
 		plan.NewCopyCommand("."),
-		plan.NewExecCommand("uv sync --locked --no-dev --no-editable"),
+		plan.NewInstallCommand("uv sync --locked --no-dev --no-editable"),
 	})
 
 	return []string{VENV_PATH}
@@ -169,7 +173,7 @@ func (p *PythonProvider) InstallPipenv(ctx *generate.GenerateContext, install *g
 
 	install.AddCommands([]plan.Command{
 		plan.NewPathCommand(LOCAL_BIN_PATH),
-		plan.NewExecCommand("pipx install pipenv"),
+		plan.NewInstallCommand("pipx install pipenv"),
 		plan.NewPathCommand(VENV_PATH + "/bin"),
 	})
 
@@ -177,12 +181,12 @@ func (p *PythonProvider) InstallPipenv(ctx *generate.GenerateContext, install *g
 		install.AddCommands([]plan.Command{
 			plan.NewCopyCommand("Pipfile"),
 			plan.NewCopyCommand("Pipfile.lock"),
-			plan.NewExecCommand("pipenv install --deploy --ignore-pipfile"),
+			plan.NewInstallCommand("pipenv install --deploy --ignore-pipfile"),
 		})
 	} else {
 		install.AddCommands([]plan.Command{
 			plan.NewCopyCommand("Pipfile"),
-			plan.NewExecCommand("pipenv install --skip-lock"),
+			plan.NewInstallCommand("pipenv install --skip-lock"),
 		})
 	}
 
@@ -199,10 +203,10 @@ func (p *PythonProvider) InstallPDM(ctx *generate.GenerateContext, install *gene
 
 	install.AddCommands([]plan.Command{
 		plan.NewPathCommand(LOCAL_BIN_PATH),
-		plan.NewExecCommand("pipx install pdm"),
+		plan.NewInstallCommand("pipx install pdm"),
 		plan.NewCopyCommand("."),
 		plan.NewExecCommand("python --version"),
-		plan.NewExecCommand("pdm install --check --prod --no-editable"),
+		plan.NewInstallCommand("pdm install --check --prod --no-editable"),
 		plan.NewPathCommand(VENV_PATH + "/bin"),
 	})
 
@@ -220,11 +224,11 @@ func (p *PythonProvider) InstallPoetry(ctx *generate.GenerateContext, install *g
 
 	install.AddCommands([]plan.Command{
 		plan.NewPathCommand(LOCAL_BIN_PATH),
-		plan.NewExecCommand("pipx install poetry"),
+		plan.NewInstallCommand("pipx install poetry"),
 		plan.NewPathCommand(VENV_PATH + "/bin"),
 		plan.NewCopyCommand("pyproject.toml"),
 		plan.NewCopyCommand("poetry.lock"),
-		plan.NewExecCommand("poetry install --no-interaction --no-ansi --only main --no-root"),
+		plan.NewInstallCommand("poetry install --no-interaction --no-ansi --only main --no-root"),
 		plan.NewCopyCommand("."),
 	})
 
@@ -239,7 +243,7 @@ func (p *PythonProvider) InstallPip(ctx *generate.GenerateContext, install *gene
 		plan.NewExecCommand(fmt.Sprintf("python -m venv %s", VENV_PATH)),
 		plan.NewPathCommand(VENV_PATH + "/bin"),
 		plan.NewCopyCommand("requirements.txt"),
-		plan.NewExecCommand("pip install -r requirements.txt"),
+		plan.NewInstallCommand("pip install -r requirements.txt"),
 	})
 	maps.Copy(install.Variables, p.GetPythonEnvVars(ctx))
 	maps.Copy(install.Variables, map[string]string{
@@ -86,6 +86,10 @@ func (p *RubyProvider) Plan(ctx *generate.GenerateContext) error {
 	ctx.Deploy.StartCmd = p.GetStartCommand(ctx)
 	maps.Copy(ctx.Deploy.Variables, p.GetRubyEnvVars(ctx))
 
+	if p.usesRails(ctx) {
+		ctx.Deploy.Ports = []string{"3000"}
+	}
+
 	ctx.Deploy.Inputs = []plan.Input{
 		p.GetImageWithRuntimeDeps(ctx),
 		plan.NewStepInput(miseStep.Name(), plan.InputOptions{
@@ -150,7 +154,7 @@ func (p *RubyProvider) Install(ctx *generate.GenerateContext, install *generate.
 	install.AddEnvVars(envVars)
 	bundlerVersion := parseBundlerVersionFromGemfile(ctx)
 	commands := []plan.Command{
-		plan.NewExecCommand(fmt.Sprintf("gem install -N %s", bundlerVersion)),
+		plan.NewInstallCommand(fmt.Sprintf("gem install -N %s", bundlerVersion)),
 		plan.NewCopyCommand("Gemfile"),
 		plan.NewCopyCommand("Gemfile.lock"),
 	}
@@ -159,7 +163,7 @@ func (p *RubyProvider) Install(ctx *generate.GenerateContext, install *generate.
 		commands = append(commands, plan.NewCopyCommand(path))
 	}
 
-	commands = append(commands, plan.NewExecCommand("bundle install"))
+	commands = append(commands, plan.NewInstallCommand("bundle install"))
 
 	if p.usesDep(ctx, "bootsnap") {
 		commands = append(commands, plan.NewExecCommand("bundle exec bootsnap precompile --gemfile"))
@@ -16,7 +16,7 @@ const (
 	DEFAULT_RUST_VERSION = "1.85.1"
 	CARGO_REGISTRY_CACHE = "/root/.cargo/registry"
 	CARGO_GIT_CACHE      = "/root/.cargo/git"
-	CARGO_TARGET_CACHE   = "target"
+	CARGO_TARGET_CACHE   = "/app/target"
 )
 
 type RustProvider struct {
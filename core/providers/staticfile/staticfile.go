@@ -71,6 +71,7 @@ func (p *StaticfileProvider) Plan(ctx *generate.GenerateContext) error {
 	}
 
 	ctx.Deploy.StartCmd = p.CaddyStartCommand(ctx)
+	ctx.Deploy.Ports = []string{"8080"}
 
 	return nil
 }
@@ -3,6 +3,7 @@ package staticfile
 import (
 	"testing"
 
+	"github.com/railwayapp/railpack/core/plan"
 	testingUtils "github.com/railwayapp/railpack/core/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -99,3 +100,17 @@ func TestGetRootDir(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanDeploy(t *testing.T) {
+	ctx := testingUtils.CreateGenerateContext(t, "../../../examples/staticfile-index")
+	provider := StaticfileProvider{}
+
+	detected, err := provider.Detect(ctx)
+	require.NoError(t, err)
+	require.True(t, detected)
+	require.NoError(t, provider.Plan(ctx))
+
+	// Caddy listens on an unprivileged port so that the default non-root user can run it
+	require.Equal(t, []string{"8080"}, ctx.Deploy.Ports)
+	require.Equal(t, plan.RAILPACK_USER, ctx.Deploy.User)
+}
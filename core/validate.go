@@ -2,6 +2,10 @@ package core
 
 import (
 	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
 
 	"github.com/railwayapp/railpack/core/app"
 	"github.com/railwayapp/railpack/core/logger"
@@ -30,7 +34,238 @@ func ValidatePlan(plan *plan.BuildPlan, app *app.App, logger *logger.Logger, opt
 		}
 	}
 
-	return validateInputs(plan.Deploy.Inputs, "deploy", logger)
+	if !validateInputs(plan.Deploy.Inputs, "deploy", logger) {
+		return false
+	}
+
+	if !validateStepGraph(plan, logger) {
+		return false
+	}
+
+	if !validateCaches(plan, logger) {
+		return false
+	}
+
+	for _, step := range plan.Steps {
+		if !validateStepReferences(plan, &step, logger) {
+			return false
+		}
+
+		if !validateStepCommands(&step, logger) {
+			return false
+		}
+	}
+
+	return validateDeploy(plan, logger)
+}
+
+// validateStepGraph checks that
+// 1. step names are unique
+// 2. every step input (including the deploy inputs) references a step in the plan
+// 3. the steps do not depend on each other in a cycle
+// 4. every step is used by the deploy, either directly or through other steps (only a warning)
+func validateStepGraph(buildPlan *plan.BuildPlan, logger *logger.Logger) bool {
+	steps := make(map[string]*plan.Step, len(buildPlan.Steps))
+	for i := range buildPlan.Steps {
+		step := &buildPlan.Steps[i]
+		if _, exists := steps[step.Name]; exists {
+			logger.LogError("step %s is defined more than once", step.Name)
+			return false
+		}
+		steps[step.Name] = step
+	}
+
+	for _, step := range buildPlan.Steps {
+		for _, input := range step.Inputs {
+			if input.Step != "" && steps[input.Step] == nil {
+				logger.LogError("step %s references step %s which does not exist", step.Name, input.Step)
+				return false
+			}
+		}
+	}
+
+	for _, input := range buildPlan.Deploy.Inputs {
+		if input.Step != "" && steps[input.Step] == nil {
+			logger.LogError("deploy references step %s which does not exist", input.Step)
+			return false
+		}
+	}
+
+	if cycle := findStepCycle(buildPlan.Steps, steps); cycle != nil {
+		logger.LogError("steps %s depend on each other in a cycle", strings.Join(cycle, " -> "))
+		return false
+	}
+
+	// Steps are only built when the deploy depends on them
+	reachable := map[string]bool{}
+	var visit func(inputs []plan.Input)
+	visit = func(inputs []plan.Input) {
+		for _, input := range inputs {
+			if input.Step == "" || reachable[input.Step] {
+				continue
+			}
+			reachable[input.Step] = true
+			visit(steps[input.Step].Inputs)
+		}
+	}
+	visit(buildPlan.Deploy.Inputs)
+
+	for _, step := range buildPlan.Steps {
+		if !reachable[step.Name] {
+			logger.LogWarn("step %s is not used by the deploy and will not be built", step.Name)
+		}
+	}
+
+	return true
+}
+
+// validateCaches checks that every cache directory is an absolute path
+func validateCaches(buildPlan *plan.BuildPlan, logger *logger.Logger) bool {
+	for _, name := range slices.Sorted(maps.Keys(buildPlan.Caches)) {
+		cache := buildPlan.Caches[name]
+		if cache == nil || !path.IsAbs(cache.Directory) {
+			directory := ""
+			if cache != nil {
+				directory = cache.Directory
+			}
+
+			logger.LogError("cache %s must have an absolute directory, got %q", name, directory)
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateStepReferences checks that
+// 1. every cache used by the step is defined in the plan caches
+// 2. every secret used by the step is defined in the plan secrets
+// 3. every file command references an asset of the step
+func validateStepReferences(buildPlan *plan.BuildPlan, step *plan.Step, logger *logger.Logger) bool {
+	for _, cacheKey := range step.Caches {
+		if _, ok := buildPlan.Caches[cacheKey]; !ok {
+			logger.LogError("step %s uses cache %s which is not defined in the plan caches", step.Name, cacheKey)
+			return false
+		}
+	}
+
+	for _, secret := range step.Secrets {
+		if secret != "*" && !slices.Contains(buildPlan.Secrets, secret) {
+			logger.LogError("step %s uses secret %s which is not defined in the plan secrets.\n\nAdd it to the secrets in the config file or set it as an environment variable", step.Name, secret)
+			return false
+		}
+	}
+
+	for _, cmd := range step.Commands {
+		if fileCmd, ok := cmd.(plan.FileCommand); ok {
+			if _, ok := step.Assets[fileCmd.Name]; !ok {
+				logger.LogError("step %s creates file %s from asset %s which is not defined in the step assets", step.Name, fileCmd.Path, fileCmd.Name)
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// validateStepCommands checks that
+// 1. every exec command of the step has valid retries, durations, user, and network mode
+// 2. every variable command sets a valid variable name
+func validateStepCommands(step *plan.Step, logger *logger.Logger) bool {
+	for _, cmd := range step.Commands {
+		switch cmd := cmd.(type) {
+		case plan.ExecCommand:
+			if err := cmd.Validate(); err != nil {
+				logger.LogError("step %s has an invalid command `%s`: %s", step.Name, cmd.Cmd, err.Error())
+				return false
+			}
+		case plan.VariableCommand:
+			if err := cmd.Validate(); err != nil {
+				logger.LogError("step %s has an invalid variable command: %s", step.Name, err.Error())
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// findStepCycle returns the names of the steps in the first cycle found, starting and ending with the same step
+func findStepCycle(stepList []plan.Step, steps map[string]*plan.Step) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(stepList))
+	path := []string{}
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			start := slices.Index(path, name)
+			return append(slices.Clone(path[start:]), name)
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+
+		for _, input := range steps[name].Inputs {
+			if input.Step == "" {
+				continue
+			}
+			if cycle := visit(input.Step); cycle != nil {
+				return cycle
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, step := range stepList {
+		if cycle := visit(step.Name); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+// validateDeploy checks that
+// 1. the deploy user is a valid user name or uid:gid
+// 2. the ports are valid port numbers with an optional protocol
+// 3. the healthcheck has a command and valid durations
+func validateDeploy(buildPlan *plan.BuildPlan, logger *logger.Logger) bool {
+	deploy := buildPlan.Deploy
+
+	if deploy.User != "" {
+		if _, err := plan.ParseUser(deploy.User); err != nil {
+			logger.LogError("%s", err.Error())
+			return false
+		}
+	}
+
+	for _, port := range deploy.Ports {
+		if _, err := plan.ParsePort(port); err != nil {
+			logger.LogError("%s", err.Error())
+			return false
+		}
+	}
+
+	if deploy.Healthcheck != nil {
+		if err := deploy.Healthcheck.Validate(); err != nil {
+			logger.LogError("%s", err.Error())
+			return false
+		}
+	}
+
+	return true
 }
 
 // validateCommands checks if the plan has at least one command
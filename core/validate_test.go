@@ -111,3 +111,189 @@ func TestValidateInputs(t *testing.T) {
 		require.False(t, validateInputs(inputs, "test", logger))
 	})
 }
+
+func TestValidateDeploy(t *testing.T) {
+	logger := logger.NewLogger()
+
+	t.Run("valid deploy", func(t *testing.T) {
+		buildPlan := plan.NewBuildPlan()
+		buildPlan.Deploy = plan.Deploy{
+			User:        "1000:1000",
+			Ports:       []string{"80", "53/udp"},
+			Healthcheck: &plan.Healthcheck{Cmd: "curl -f http://localhost/", Interval: "30s", Retries: 3},
+		}
+		require.True(t, validateDeploy(buildPlan, logger))
+	})
+
+	t.Run("invalid user", func(t *testing.T) {
+		buildPlan := plan.NewBuildPlan()
+		buildPlan.Deploy = plan.Deploy{User: "1000:abc"}
+		require.False(t, validateDeploy(buildPlan, logger))
+	})
+
+	t.Run("invalid port", func(t *testing.T) {
+		buildPlan := plan.NewBuildPlan()
+		buildPlan.Deploy = plan.Deploy{Ports: []string{"80/http"}}
+		require.False(t, validateDeploy(buildPlan, logger))
+	})
+
+	t.Run("healthcheck without command", func(t *testing.T) {
+		buildPlan := plan.NewBuildPlan()
+		buildPlan.Deploy = plan.Deploy{Healthcheck: &plan.Healthcheck{Interval: "30s"}}
+		require.False(t, validateDeploy(buildPlan, logger))
+	})
+
+	t.Run("healthcheck with invalid interval", func(t *testing.T) {
+		buildPlan := plan.NewBuildPlan()
+		buildPlan.Deploy = plan.Deploy{Healthcheck: &plan.Healthcheck{Cmd: "true", Interval: "30"}}
+		require.False(t, validateDeploy(buildPlan, logger))
+	})
+}
+
+func TestValidateStepGraph(t *testing.T) {
+	newPlan := func(steps map[string][]string, deploySteps ...string) *plan.BuildPlan {
+		buildPlan := plan.NewBuildPlan()
+		for _, name := range []string{"install", "build", "prune"} {
+			parents, ok := steps[name]
+			if !ok {
+				continue
+			}
+
+			step := plan.NewStep(name)
+			step.Inputs = []plan.Input{plan.NewImageInput("node:18")}
+			for _, parent := range parents {
+				step.Inputs = append(step.Inputs, plan.NewStepInput(parent))
+			}
+			buildPlan.AddStep(*step)
+		}
+
+		buildPlan.Deploy.Inputs = []plan.Input{plan.NewImageInput("node:18")}
+		for _, name := range deploySteps {
+			buildPlan.Deploy.Inputs = append(buildPlan.Deploy.Inputs, plan.NewStepInput(name))
+		}
+		return buildPlan
+	}
+
+	lastLog := func(logger *logger.Logger) string {
+		return logger.Logs[len(logger.Logs)-1].Msg
+	}
+
+	t.Run("valid graph", func(t *testing.T) {
+		logger := logger.NewLogger()
+		buildPlan := newPlan(map[string][]string{"install": {}, "build": {"install"}}, "build")
+		require.True(t, validateStepGraph(buildPlan, logger))
+		require.Empty(t, logger.Logs)
+	})
+
+	t.Run("unknown step reference", func(t *testing.T) {
+		logger := logger.NewLogger()
+		buildPlan := newPlan(map[string][]string{"install": {}, "build": {"isntall"}}, "build")
+		require.False(t, validateStepGraph(buildPlan, logger))
+		require.Equal(t, "step build references step isntall which does not exist", lastLog(logger))
+	})
+
+	t.Run("deploy references missing step", func(t *testing.T) {
+		logger := logger.NewLogger()
+		buildPlan := newPlan(map[string][]string{"install": {}}, "biuld")
+		require.False(t, validateStepGraph(buildPlan, logger))
+		require.Equal(t, "deploy references step biuld which does not exist", lastLog(logger))
+	})
+
+	t.Run("duplicate steps", func(t *testing.T) {
+		logger := logger.NewLogger()
+		buildPlan := newPlan(map[string][]string{"install": {}}, "install")
+		buildPlan.AddStep(*plan.NewStep("install"))
+		require.False(t, validateStepGraph(buildPlan, logger))
+		require.Equal(t, "step install is defined more than once", lastLog(logger))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		logger := logger.NewLogger()
+		buildPlan := newPlan(map[string][]string{"install": {"prune"}, "build": {"install"}, "prune": {"build"}}, "build")
+		require.False(t, validateStepGraph(buildPlan, logger))
+		require.Equal(t, "steps install -> prune -> build -> install depend on each other in a cycle", lastLog(logger))
+	})
+
+	t.Run("unreachable step", func(t *testing.T) {
+		logger := logger.NewLogger()
+		buildPlan := newPlan(map[string][]string{"install": {}, "build": {"install"}, "prune": {"install"}}, "build")
+		require.True(t, validateStepGraph(buildPlan, logger))
+		require.Equal(t, "step prune is not used by the deploy and will not be built", lastLog(logger))
+	})
+}
+
+func TestValidateStepReferences(t *testing.T) {
+	newPlan := func() (*plan.BuildPlan, *plan.Step) {
+		buildPlan := plan.NewBuildPlan()
+		buildPlan.Caches["npm"] = plan.NewCache("/root/.npm")
+		buildPlan.Secrets = []string{"NPM_TOKEN"}
+
+		step := plan.NewStep("install")
+		step.Caches = []string{"npm"}
+		step.Secrets = []string{"NPM_TOKEN"}
+		step.Assets["npmrc"] = "registry=https://registry.npmjs.org/"
+		step.Commands = []plan.Command{plan.NewFileCommand("/app/.npmrc", "npmrc")}
+		return buildPlan, step
+	}
+
+	t.Run("valid references", func(t *testing.T) {
+		logger := logger.NewLogger()
+		buildPlan, step := newPlan()
+		require.True(t, validateStepReferences(buildPlan, step, logger))
+
+		step.Secrets = []string{"*"}
+		require.True(t, validateStepReferences(buildPlan, step, logger))
+	})
+
+	t.Run("undefined cache", func(t *testing.T) {
+		logger := logger.NewLogger()
+		buildPlan, step := newPlan()
+		step.Caches = []string{"pnpm"}
+		require.False(t, validateStepReferences(buildPlan, step, logger))
+		require.Equal(t, "step install uses cache pnpm which is not defined in the plan caches", logger.Logs[0].Msg)
+	})
+
+	t.Run("undefined secret", func(t *testing.T) {
+		logger := logger.NewLogger()
+		buildPlan, step := newPlan()
+		step.Secrets = []string{"GITHUB_TOKEN"}
+		require.False(t, validateStepReferences(buildPlan, step, logger))
+		require.Contains(t, logger.Logs[0].Msg, "step install uses secret GITHUB_TOKEN which is not defined in the plan secrets")
+	})
+
+	t.Run("undefined asset", func(t *testing.T) {
+		logger := logger.NewLogger()
+		buildPlan, step := newPlan()
+		step.Commands = append(step.Commands, plan.NewFileCommand("/app/.yarnrc", "yarnrc"))
+		require.False(t, validateStepReferences(buildPlan, step, logger))
+		require.Equal(t, "step install creates file /app/.yarnrc from asset yarnrc which is not defined in the step assets", logger.Logs[0].Msg)
+	})
+}
+
+func TestValidateStepCommands(t *testing.T) {
+	logger := logger.NewLogger()
+
+	step := plan.NewStep("install")
+	step.Commands = []plan.Command{plan.NewInstallCommand("npm ci")}
+	require.True(t, validateStepCommands(step, logger))
+
+	step.Commands = append(step.Commands, plan.NewExecCommand("npm run build", plan.ExecOptions{Timeout: "forever"}))
+	require.False(t, validateStepCommands(step, logger))
+	require.Equal(t, "step install has an invalid command `npm run build`: invalid command timeout `forever`: expected a duration such as 30s", logger.Logs[0].Msg)
+
+	step.Commands = []plan.Command{plan.NewVariableCommand("JAVA HOME", "/opt/java")}
+	require.False(t, validateStepCommands(step, logger))
+	require.Contains(t, logger.Logs[1].Msg, "step install has an invalid variable command: invalid variable name `JAVA HOME`")
+}
+
+func TestValidateCaches(t *testing.T) {
+	logger := logger.NewLogger()
+
+	buildPlan := plan.NewBuildPlan()
+	buildPlan.Caches["npm"] = plan.NewCache("/root/.npm")
+	require.True(t, validateCaches(buildPlan, logger))
+
+	buildPlan.Caches["vite"] = plan.NewCache("node_modules/.vite")
+	require.False(t, validateCaches(buildPlan, logger))
+	require.Equal(t, `cache vite must have an absolute directory, got "node_modules/.vite"`, logger.Logs[0].Msg)
+}
@@ -0,0 +1,48 @@
+package integration_tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/railwayapp/railpack/buildkit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheExportImportRoundTrip(t *testing.T) {
+	requireBuildkit(t)
+
+	examplePath, buildResult := generateExamplePlan(t, "shell-script")
+	cacheDir := t.TempDir()
+	cacheKey := fmt.Sprintf("railpack-test-cache-%s", uuid.New().String())
+
+	// The first build exports every layer to a local cache directory
+	err := buildkit.BuildWithBuildkitClient(examplePath, buildResult.Plan, buildkit.BuildWithBuildkitClientOptions{
+		ImageName:   cacheKey,
+		OutputDir:   t.TempDir(),
+		ExportCache: []string{fmt.Sprintf("type=local,dest=%s,mode=max", cacheDir)},
+		CacheKey:    cacheKey,
+	})
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(cacheDir, "index.json"))
+
+	// The second build imports the cache and exports it again, which must leave a valid cache
+	reexportDir := t.TempDir()
+	outputDir := t.TempDir()
+	err = buildkit.BuildWithBuildkitClient(examplePath, buildResult.Plan, buildkit.BuildWithBuildkitClientOptions{
+		ImageName:   cacheKey,
+		OutputDir:   outputDir,
+		ImportCache: []string{fmt.Sprintf("type=local,src=%s", cacheDir)},
+		ExportCache: []string{fmt.Sprintf("type=local,dest=%s,mode=max", reexportDir)},
+		CacheKey:    cacheKey,
+	})
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(reexportDir, "index.json"))
+
+	// The build output contains the app
+	entries, err := os.ReadDir(filepath.Join(outputDir, "app"))
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+}
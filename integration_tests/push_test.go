@@ -0,0 +1,84 @@
+package integration_tests
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/railwayapp/railpack/buildkit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushToRegistry(t *testing.T) {
+	requireBuildkit(t)
+
+	registry := startRegistry(t)
+	examplePath, buildResult := generateExamplePlan(t, "shell-script")
+
+	repository := fmt.Sprintf("railpack-test-push-%s", uuid.New().String())
+	imageName := fmt.Sprintf("%s/%s:latest", registry.host, repository)
+
+	err := buildkit.BuildWithBuildkitClient(examplePath, buildResult.Plan, buildkit.BuildWithBuildkitClientOptions{
+		ImageName: imageName,
+		Push:      true,
+		CacheKey:  repository,
+	})
+	require.NoError(t, err)
+
+	tags, err := registry.get(fmt.Sprintf("/v2/%s/tags/list", repository))
+	require.NoError(t, err)
+	require.Contains(t, tags, `"latest"`)
+}
+
+type testRegistry struct {
+	container string
+	host      string
+}
+
+// startRegistry runs a registry:2 container in the network namespace of the BuildKit container,
+// so that BuildKit can push to it over plain HTTP on localhost
+func startRegistry(t *testing.T) *testRegistry {
+	t.Helper()
+
+	buildkitContainer, found := strings.CutPrefix(os.Getenv("BUILDKIT_HOST"), "docker-container://")
+	if !found {
+		t.Skip("pushing to a local registry requires a docker-container:// BUILDKIT_HOST")
+	}
+
+	registry := &testRegistry{
+		container: fmt.Sprintf("railpack-test-registry-%s", uuid.New().String()),
+		host:      fmt.Sprintf("localhost:%d", 20000+rand.IntN(10000)),
+	}
+
+	output, err := exec.Command("docker", "run", "-d", "--rm",
+		"--name", registry.container,
+		"--network", "container:"+buildkitContainer,
+		"-e", "REGISTRY_HTTP_ADDR="+registry.host,
+		"registry:2").CombinedOutput()
+	require.NoError(t, err, "failed to start registry: %s", output)
+
+	t.Cleanup(func() {
+		_ = exec.Command("docker", "rm", "-f", registry.container).Run()
+	})
+
+	require.Eventually(t, func() bool {
+		_, err := registry.get("/v2/")
+		return err == nil
+	}, 30*time.Second, 500*time.Millisecond, "registry did not start")
+
+	return registry
+}
+
+// get requests a path of the registry API from inside the registry container
+func (r *testRegistry) get(path string) (string, error) {
+	output, err := exec.Command("docker", "exec", r.container, "wget", "-q", "-O", "-", "http://"+r.host+path).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, output)
+	}
+	return string(output), nil
+}
@@ -91,8 +91,8 @@ func TestExamplesIntegration(t *testing.T) {
 
 				if err := buildkit.BuildWithBuildkitClient(examplePath, buildResult.Plan, buildkit.BuildWithBuildkitClientOptions{
 					ImageName:   imageName,
-					ImportCache: *buildkitCacheImport,
-					ExportCache: *buildkitCacheExport,
+					ImportCache: cacheSpecs(*buildkitCacheImport),
+					ExportCache: cacheSpecs(*buildkitCacheExport),
 					Secrets:     testCase.Envs,
 					CacheKey:    imageName,
 				}); err != nil {
@@ -198,3 +198,41 @@ func runContainerWithTimeout(t *testing.T, imageName, expectedOutput string, env
 		return nil
 	}
 }
+
+// requireBuildkit skips tests that build images unless a BuildKit daemon is configured
+func requireBuildkit(t *testing.T) {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	if os.Getenv("BUILDKIT_HOST") == "" {
+		t.Skip("BUILDKIT_HOST is not set")
+	}
+}
+
+// generateExamplePlan generates the build plan of an example app
+func generateExamplePlan(t *testing.T, name string) (string, *core.BuildResult) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	examplePath := filepath.Join(filepath.Dir(wd), "examples", name)
+	userApp, err := app.NewApp(examplePath)
+	require.NoError(t, err)
+
+	buildResult := core.GenerateBuildPlan(userApp, app.NewEnvironment(nil), &core.GenerateBuildPlanOptions{})
+	require.True(t, buildResult.Success, "failed to generate build plan: %v", buildResult.Logs)
+
+	return examplePath, buildResult
+}
+
+// cacheSpecs wraps a single cache flag value in the list the build options expect
+func cacheSpecs(spec string) []string {
+	if spec == "" {
+		return nil
+	}
+	return []string{spec}
+}
@@ -0,0 +1,291 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	validator "github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// The location the schema is compiled from. Schemas are never loaded from it
+const schemaLocation = "railpack:schema.json"
+
+// SchemaError is a value that does not match a JSON schema
+type SchemaError struct {
+	// JSON pointer to the value (e.g. /steps/0/commands/1)
+	Pointer string
+
+	// The name of the property if the error is about an unknown or missing property
+	Property string
+
+	// A known property with a similar name if the property is unknown
+	Suggestion string
+
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	pointer := e.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return fmt.Sprintf("%s: %s", pointer, e.Description())
+}
+
+// Description is the message of the error followed by the suggestion, if there is one
+func (e SchemaError) Description() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("%s. Did you mean %q?", e.Message, e.Suggestion)
+	}
+	return e.Message
+}
+
+// ValidateJSONSchema validates a decoded JSON value against a schema and returns every mismatch sorted by their location
+func ValidateJSONSchema(schema *jsonschema.Schema, value any) ([]SchemaError, error) {
+	schemaBytes, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	rawSchema, err := validator.UnmarshalJSON(bytes.NewReader(schemaBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := validator.NewCompiler()
+	if err := compiler.AddResource(schemaLocation, rawSchema); err != nil {
+		return nil, err
+	}
+
+	compiled, err := compiler.Compile(schemaLocation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema: %w", err)
+	}
+
+	err = compiled.Validate(value)
+
+	var validationError *validator.ValidationError
+	if err != nil && !errors.As(err, &validationError) {
+		return nil, err
+	}
+
+	schemaErrors := []SchemaError{}
+	if validationError != nil {
+		c := &schemaErrorCollector{schema: rawSchema, value: value}
+		schemaErrors = c.collect(validationError)
+	}
+
+	slices.SortStableFunc(schemaErrors, func(a, b SchemaError) int {
+		return strings.Compare(a.Pointer, b.Pointer)
+	})
+
+	return schemaErrors, nil
+}
+
+// JSONPointer appends an escaped token to a JSON pointer
+func JSONPointer(pointer string, token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	token = strings.ReplaceAll(token, "/", "~1")
+	return pointer + "/" + token
+}
+
+// schemaErrorCollector converts the tree of validation errors into one error per invalid value
+type schemaErrorCollector struct {
+	schema any
+	value  any
+}
+
+var messagePrinter = message.NewPrinter(language.English)
+
+func (c *schemaErrorCollector) collect(err *validator.ValidationError) []SchemaError {
+	pointer := ""
+	for _, token := range err.InstanceLocation {
+		pointer = JSONPointer(pointer, token)
+	}
+
+	switch k := err.ErrorKind.(type) {
+	case *kind.Group, *kind.Schema, *kind.Reference, *kind.AllOf:
+		errors := []SchemaError{}
+		for _, cause := range err.Causes {
+			errors = append(errors, c.collect(cause)...)
+		}
+		return errors
+
+	case *kind.AnyOf, *kind.OneOf:
+		if oneOf, ok := k.(*kind.OneOf); ok && len(oneOf.Subschemas) > 0 {
+			return []SchemaError{{Pointer: pointer, Message: "value matches more than one of the allowed formats"}}
+		}
+
+		// If only one alternative has the same type as the value, its errors are returned since they are the most helpful
+		candidates := []*validator.ValidationError{}
+		for _, cause := range err.Causes {
+			if !c.isTypeMismatch(cause, len(err.InstanceLocation)) {
+				candidates = append(candidates, cause)
+			}
+		}
+		if len(candidates) == 1 {
+			return c.collect(candidates[0])
+		}
+
+		return []SchemaError{{Pointer: pointer, Message: "value does not match any of the allowed formats"}}
+
+	case *kind.AdditionalProperties:
+		properties := slices.Sorted(maps.Keys(c.schemaProperties(err.SchemaURL)))
+
+		errors := []SchemaError{}
+		for _, name := range slices.Sorted(slices.Values(k.Properties)) {
+			errors = append(errors, SchemaError{
+				Pointer:    JSONPointer(pointer, name),
+				Property:   name,
+				Suggestion: ClosestMatch(name, properties),
+				Message:    fmt.Sprintf("unknown property %q", name),
+			})
+		}
+		return errors
+
+	case *kind.Required:
+		errors := []SchemaError{}
+		for _, name := range k.Missing {
+			errors = append(errors, SchemaError{Pointer: pointer, Property: name, Message: fmt.Sprintf("missing required property %q", name)})
+		}
+		return errors
+
+	case *kind.Type:
+		return []SchemaError{{Pointer: pointer, Message: fmt.Sprintf("expected %s but got %s", strings.Join(k.Want, " or "), jsonType(c.valueAt(err.InstanceLocation)))}}
+
+	case *kind.Enum:
+		return []SchemaError{{Pointer: pointer, Message: fmt.Sprintf("must be one of %s", formatJSONValues(k.Want))}}
+
+	case *kind.Const:
+		return []SchemaError{{Pointer: pointer, Message: fmt.Sprintf("must be %s", formatJSONValues([]any{k.Want}))}}
+
+	case *kind.Pattern:
+		return []SchemaError{{Pointer: pointer, Message: fmt.Sprintf("must match the pattern %s", k.Want)}}
+
+	case *kind.FalseSchema:
+		return []SchemaError{{Pointer: pointer, Message: "value is not allowed"}}
+
+	default:
+		return []SchemaError{{Pointer: pointer, Message: err.ErrorKind.LocalizedString(messagePrinter)}}
+	}
+}
+
+// isTypeMismatch returns whether an alternative failed because the value at the given depth is a different type altogether.
+// Objects that are missing every required property are also considered a different format
+func (c *schemaErrorCollector) isTypeMismatch(err *validator.ValidationError, depth int) bool {
+	if len(err.InstanceLocation) != depth {
+		return false
+	}
+
+	switch k := err.ErrorKind.(type) {
+	case *kind.Type:
+		return true
+	case *kind.Required:
+		required, _ := c.schemaAt(err.SchemaURL)["required"].([]any)
+		return len(k.Missing) == len(required)
+	case *kind.Group, *kind.Schema, *kind.Reference:
+		return slices.ContainsFunc(err.Causes, func(cause *validator.ValidationError) bool {
+			return c.isTypeMismatch(cause, depth)
+		})
+	default:
+		return false
+	}
+}
+
+// schemaAt returns the schema at a location within the compiled schema (e.g. railpack:schema.json#/properties/steps)
+func (c *schemaErrorCollector) schemaAt(location string) map[string]any {
+	_, fragment, _ := strings.Cut(location, "#")
+
+	current := c.schema
+	for _, token := range strings.Split(strings.TrimPrefix(fragment, "/"), "/") {
+		if token == "" {
+			continue
+		}
+
+		token, _ = url.PathUnescape(token)
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch v := current.(type) {
+		case map[string]any:
+			current = v[token]
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			current = v[i]
+		default:
+			return nil
+		}
+	}
+
+	schema, _ := current.(map[string]any)
+	return schema
+}
+
+func (c *schemaErrorCollector) schemaProperties(location string) map[string]any {
+	properties, _ := c.schemaAt(location)["properties"].(map[string]any)
+	return properties
+}
+
+func (c *schemaErrorCollector) valueAt(location []string) any {
+	current := c.value
+	for _, token := range location {
+		switch v := current.(type) {
+		case map[string]any:
+			current = v[token]
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			current = v[i]
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func formatJSONValues(values []any) string {
+	formatted := make([]string, 0, len(values))
+	for _, value := range values {
+		bytes, _ := json.Marshal(value)
+		formatted = append(formatted, string(bytes))
+	}
+	return strings.Join(formatted, ", ")
+}
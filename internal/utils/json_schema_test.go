@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+	"github.com/stretchr/testify/require"
+)
+
+type testSchemaConfig struct {
+	Name    string            `json:"name"`
+	Mode    string            `json:"mode,omitempty" jsonschema:"enum=fast,enum=slow"`
+	Retries int               `json:"retries,omitempty"`
+	Tags    []string          `json:"tags,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+func TestValidateJSONSchema(t *testing.T) {
+	r := jsonschema.Reflector{DoNotReference: true, Anonymous: true}
+	schema := r.Reflect(&testSchemaConfig{})
+
+	tests := []struct {
+		name  string
+		input string
+		want  []SchemaError
+	}{
+		{
+			name:  "valid",
+			input: `{"name": "app", "mode": "fast", "retries": 3, "tags": ["a"], "env": {"A": "b"}}`,
+			want:  []SchemaError{},
+		},
+		{
+			name:  "missing required property",
+			input: `{}`,
+			want:  []SchemaError{{Pointer: "", Property: "name", Message: `missing required property "name"`}},
+		},
+		{
+			name:  "unknown property",
+			input: `{"name": "app", "nmae": "app"}`,
+			want:  []SchemaError{{Pointer: "/nmae", Property: "nmae", Suggestion: "name", Message: `unknown property "nmae"`}},
+		},
+		{
+			name:  "unknown property without a similar property",
+			input: `{"name": "app", "timeout": 30}`,
+			want:  []SchemaError{{Pointer: "/timeout", Property: "timeout", Message: `unknown property "timeout"`}},
+		},
+		{
+			name:  "wrong types",
+			input: `{"name": "app", "retries": 1.5, "tags": ["a", 1], "env": {"A": true}}`,
+			want: []SchemaError{
+				{Pointer: "/env/A", Message: "expected string but got boolean"},
+				{Pointer: "/retries", Message: "expected integer but got number"},
+				{Pointer: "/tags/1", Message: "expected string but got integer"},
+			},
+		},
+		{
+			name:  "enum",
+			input: `{"name": "app", "mode": "medium"}`,
+			want:  []SchemaError{{Pointer: "/mode", Message: `must be one of "fast", "slow"`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			require.NoError(t, json.Unmarshal([]byte(tt.input), &value))
+
+			got, err := ValidateJSONSchema(schema, value)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateJSONSchemaOneOf(t *testing.T) {
+	schema := &jsonschema.Schema{
+		OneOf: []*jsonschema.Schema{
+			{Type: "string"},
+			{
+				Type:       "object",
+				Properties: jsonschema.NewProperties(),
+				Required:   []string{"cmd"},
+			},
+		},
+	}
+	schema.OneOf[1].Properties.Set("cmd", &jsonschema.Schema{Type: "string"})
+
+	var value any
+	require.NoError(t, json.Unmarshal([]byte(`{"cmd": 1}`), &value))
+
+	// Only the object alternative has the same type, so its errors are reported
+	got, err := ValidateJSONSchema(schema, value)
+	require.NoError(t, err)
+	require.Equal(t, []SchemaError{{Pointer: "/cmd", Message: "expected string but got integer"}}, got)
+
+	got, err = ValidateJSONSchema(schema, true)
+	require.NoError(t, err)
+	require.Equal(t, []SchemaError{{Pointer: "", Message: "value does not match any of the allowed formats"}}, got)
+}
+
+func TestJSONPointer(t *testing.T) {
+	require.Equal(t, "/steps/a~1b~0c", JSONPointer("/steps", "a/b~c"))
+}
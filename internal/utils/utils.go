@@ -67,6 +67,49 @@ func CapitalizeFirst(s string) string {
 	return string(runes)
 }
 
+// ClosestMatch returns the candidate most similar to a value, or "" if none are close enough to be a likely typo.
+// Case is ignored so that e.g. "StartCommand" matches "startCommand"
+func ClosestMatch(value string, candidates []string) string {
+	maxDistance := max(2, len(value)/3)
+
+	closest := ""
+	closestDistance := maxDistance + 1
+	for _, candidate := range candidates {
+		distance := levenshteinDistance(strings.ToLower(value), strings.ToLower(candidate))
+		if distance < closestDistance {
+			closest = candidate
+			closestDistance = distance
+		}
+	}
+
+	return closest
+}
+
+// levenshteinDistance is the number of single character edits needed to change one string into another
+func levenshteinDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(br)]
+}
+
 // ParsePackageWithVersion parses a slice of package specifications in the format "name@version"
 // and returns a map of package names to their versions.
 // If a package has no version specified (no @ symbol), it defaults to "latest".
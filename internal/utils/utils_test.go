@@ -107,3 +107,27 @@ func TestParseSemver(t *testing.T) {
 		})
 	}
 }
+
+func TestClosestMatch(t *testing.T) {
+	candidates := []string{"startCommand", "releaseCommand", "variables", "inputs"}
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "startCmd", want: ""},
+		{value: "startComand", want: "startCommand"},
+		{value: "StartCommand", want: "startCommand"},
+		{value: "variabels", want: "variables"},
+		{value: "input", want: "inputs"},
+		{value: "caches", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := ClosestMatch(tt.value, candidates); got != tt.want {
+				t.Errorf("ClosestMatch(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}